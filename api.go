@@ -4,21 +4,27 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 )
 
+const errUnexpectedStatusCode = "unexpected status code %d"
+
 type APIClient struct {
 	Key       string
 	URL       string
 	UserAgent string
 	Client    *http.Client
+
+	httpConfig
 }
 
 // NewAPIClient prepares a client for use with the Customer.io API, see: https://customer.io/docs/api/#apicoreintroduction
 // using an App API Key from https://fly.customer.io/settings/api_credentials?keyType=app
-func NewAPIClient(key string, opts ...option) *APIClient {
+func NewAPIClient(key string, opts ...Option) *APIClient {
 	client := &APIClient{
 		Key:       key,
 		Client:    http.DefaultClient,
@@ -32,38 +38,99 @@ func NewAPIClient(key string, opts ...option) *APIClient {
 	return client
 }
 
-func (c *APIClient) doRequest(ctx context.Context, verb, requestPath string, body interface{}) ([]byte, int, error) {
-	var requestBody io.Reader
+// streamer is implemented by request bodies that are encoded while they are sent
+// rather than marshaled in memory up front.
+type streamer interface {
+	writeJSON(w io.Writer) error
+}
+
+// doRequest sends a request to the API and returns the response along with its body.
+func (c *APIClient) doRequest(ctx context.Context, verb, requestPath string, body interface{}) (*http.Response, []byte, error) {
+	stream, streaming := body.(streamer)
 
-	if body != nil {
-		b, err := json.Marshal(body)
+	var b []byte
+	if body != nil && !streaming {
+		var err error
+		b, err = json.Marshal(body)
 		if err != nil {
-			return nil, 0, err
+			return nil, nil, err
 		}
-		requestBody = bytes.NewBuffer(b)
 	}
 
-	req, err := http.NewRequest(verb, c.URL+requestPath, requestBody)
-	if err != nil {
-		return nil, 0, err
-	}
+	resp, respBody, err := doHTTP(ctx, c.Client, c.URL, c.httpConfig, func() (*http.Request, error) {
+		var requestBody io.Reader
+		if body != nil && !streaming {
+			requestBody = bytes.NewReader(b)
+		}
 
-	req = req.WithContext(ctx)
+		req, err := http.NewRequestWithContext(ctx, verb, c.URL+requestPath, requestBody)
+		if err != nil {
+			return nil, err
+		}
 
-	req.Header.Set("Authorization", "Bearer "+c.Key)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Add("User-Agent", c.UserAgent)
+		if streaming {
+			// closing the reader, which the transport and roundTrip both do, unblocks the writer.
+			pr, pw := io.Pipe()
+			go func() {
+				pw.CloseWithError(stream.writeJSON(pw))
+			}()
+			req.Body = pr
+		}
 
-	resp, err := c.Client.Do(req)
+		req.Header.Set("Authorization", "Bearer "+c.Key)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Add("User-Agent", c.UserAgent)
+		return req, nil
+	})
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
 
-	respBody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, 0, err
+	return resp, respBody, nil
+}
+
+// APIError is returned by APIClient methods when the API answers with an unexpected status code.
+type APIError struct {
+	// StatusCode is the http status code of the response.
+	StatusCode int
+	// Method is the http method of the request.
+	Method string
+	// Endpoint is the path of the request, e.g. /v1/segments/1.
+	Endpoint string
+	// Messages are the error messages decoded from the Customer.io error payload, if any.
+	Messages []string
+	// Body is the raw body of the response.
+	Body []byte
+
+	header http.Header
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf(errUnexpectedStatusCode, e.StatusCode) + ": " + e.Method + " " + e.Endpoint
+	if len(e.Messages) > 0 {
+		msg += ": " + strings.Join(e.Messages, ", ")
 	}
+	return msg
+}
+
+// RetryAfter returns the delay requested by the Retry-After header, or 0.
+func (e *APIError) RetryAfter() time.Duration { return parseRetryAfter(e.header) }
+
+// RequestID returns the request ID header of the response, useful when contacting support.
+func (e *APIError) RequestID() string { return requestID(e.header) }
 
-	return respBody, resp.StatusCode, nil
+// Is reports whether the error matches one of ErrRateLimited, ErrAuth, ErrNotFound or ErrTemporary.
+func (e *APIError) Is(target error) bool { return statusIs(e.StatusCode, target) }
+
+// newAPIError builds the error of a response. The query of requestPath is left out
+// of Endpoint, as it may hold personal data such as email addresses.
+func newAPIError(verb, requestPath string, resp *http.Response, body []byte) *APIError {
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     verb,
+		Endpoint:   strings.SplitN(requestPath, "?", 2)[0],
+		Messages:   parseErrorMessages(body),
+		Body:       body,
+		header:     resp.Header,
+	}
 }
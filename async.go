@@ -0,0 +1,261 @@
+package customerio
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrQueueFull   = errors.New("async queue is full")
+	ErrAsyncClosed = errors.New("async client is closed")
+)
+
+// AsyncConfig configures an AsyncTrackClient. Zero values take the defaults: a queue of
+// 1000 calls, 1 worker, batches of 100 calls and a flush interval of 1s.
+type AsyncConfig struct {
+	// QueueSize is the number of calls that can wait to be sent. Defaults to 1000.
+	QueueSize int
+	// Workers is the number of goroutines sending queued calls. Defaults to 1.
+	Workers int
+	// BatchSize is the number of calls a worker accumulates before sending them. Defaults to 100.
+	BatchSize int
+	// FlushInterval is the longest a call waits in a worker before being sent. Defaults to 1s.
+	FlushInterval time.Duration
+	// OnError is called from a worker goroutine for every call that failed to be delivered.
+	OnError func(item BatchItem, err error)
+}
+
+// AsyncTrackClient queues track calls in memory and sends them from background workers
+// using the v2 batch endpoint, so that callers never wait on the network.
+type AsyncTrackClient struct {
+	client *CustomerIO
+	config AsyncConfig
+
+	// ctx bounds the sends of the workers. It is canceled when Close gives up waiting.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	queue   chan BatchItem
+	flushes []chan struct{}
+	wg      sync.WaitGroup
+
+	mu      sync.RWMutex
+	closed  bool
+	pending int
+	drained chan struct{}
+}
+
+// NewAsyncTrackClient starts the background workers of an AsyncTrackClient sending through c.
+func NewAsyncTrackClient(c *CustomerIO, config AsyncConfig) *AsyncTrackClient {
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &AsyncTrackClient{
+		client:  c,
+		config:  config,
+		ctx:     ctx,
+		cancel:  cancel,
+		queue:   make(chan BatchItem, config.QueueSize),
+		drained: make(chan struct{}),
+	}
+	close(a.drained)
+
+	for i := 0; i < config.Workers; i++ {
+		flush := make(chan struct{}, 1)
+		a.flushes = append(a.flushes, flush)
+		a.wg.Add(1)
+		go a.work(flush)
+	}
+	return a
+}
+
+// Identify queues a call identifying a customer and setting their attributes.
+func (a *AsyncTrackClient) Identify(customerID string, attributes map[string]interface{}) error {
+	if customerID == "" {
+		return ParamError{Param: "customerID"}
+	}
+	return a.Enqueue(BatchIdentify(Identifier{Type: IdentifierTypeID, Value: customerID}, attributes))
+}
+
+// Track queues an event for the supplied user.
+func (a *AsyncTrackClient) Track(customerID string, eventName string, data map[string]interface{}) error {
+	if customerID == "" {
+		return ParamError{Param: "customerID"}
+	}
+	if eventName == "" {
+		return ParamError{Param: "eventName"}
+	}
+	return a.Enqueue(BatchEvent(Identifier{Type: IdentifierTypeID, Value: customerID}, eventName, data))
+}
+
+// AddDevice queues adding a device for a customer.
+func (a *AsyncTrackClient) AddDevice(customerID string, deviceID string, platform string, data map[string]interface{}) error {
+	if customerID == "" {
+		return ParamError{Param: "customerID"}
+	}
+	if _, err := newDeviceV1(deviceID, platform, data); err != nil {
+		return err
+	}
+	return a.Enqueue(BatchAddDevice(Identifier{Type: IdentifierTypeID, Value: customerID}, deviceID, platform, data))
+}
+
+// Delete queues the deletion of a customer.
+func (a *AsyncTrackClient) Delete(customerID string) error {
+	if customerID == "" {
+		return ParamError{Param: "customerID"}
+	}
+	return a.Enqueue(BatchDelete(Identifier{Type: IdentifierTypeID, Value: customerID}))
+}
+
+// Enqueue queues any batch item. It never blocks: ErrQueueFull is returned when the queue is full.
+func (a *AsyncTrackClient) Enqueue(item BatchItem) error {
+	if err := item.validate(); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return ErrAsyncClosed
+	}
+
+	select {
+	case a.queue <- item:
+		if a.pending == 0 {
+			a.drained = make(chan struct{})
+		}
+		a.pending++
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Flush sends every queued call and waits until they are delivered or ctx is done.
+func (a *AsyncTrackClient) Flush(ctx context.Context) error {
+	for _, flush := range a.flushes {
+		select {
+		case flush <- struct{}{}:
+		default:
+		}
+	}
+
+	a.mu.RLock()
+	drained := a.drained
+	a.mu.RUnlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting new calls, sends the queued ones and waits for the workers to exit
+// or ctx to be done. When ctx is done first, the sends in progress are canceled and the
+// calls that could not be delivered are passed to OnError. Calls made after Close return
+// ErrAsyncClosed.
+func (a *AsyncTrackClient) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		a.cancel()
+		return nil
+	case <-ctx.Done():
+		a.cancel()
+		return ctx.Err()
+	}
+}
+
+func (a *AsyncTrackClient) work(flush chan struct{}) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.config.FlushInterval)
+	defer ticker.Stop()
+
+	buf := make([]BatchItem, 0, a.config.BatchSize)
+	for {
+		select {
+		case item, ok := <-a.queue:
+			if !ok {
+				a.send(buf)
+				return
+			}
+			buf = append(buf, item)
+			if len(buf) >= a.config.BatchSize {
+				buf = a.send(buf)
+			}
+		case <-ticker.C:
+			buf = a.send(buf)
+		case <-flush:
+			// pick up whatever is still waiting in the queue before sending.
+		drain:
+			for {
+				select {
+				case item, ok := <-a.queue:
+					if !ok {
+						break drain
+					}
+					buf = append(buf, item)
+					if len(buf) >= a.config.BatchSize {
+						buf = a.send(buf)
+					}
+				default:
+					break drain
+				}
+			}
+			buf = a.send(buf)
+		}
+	}
+}
+
+// send delivers buf and returns it emptied for reuse.
+func (a *AsyncTrackClient) send(buf []BatchItem) []BatchItem {
+	if len(buf) == 0 {
+		return buf
+	}
+
+	results, err := a.client.Batch(a.ctx, buf)
+	if err != nil && a.config.OnError != nil {
+		for i, item := range results {
+			if item.Err != nil {
+				a.config.OnError(buf[i], item.Err)
+			}
+		}
+	}
+
+	a.mu.Lock()
+	a.pending -= len(buf)
+	if a.pending == 0 {
+		close(a.drained)
+	}
+	a.mu.Unlock()
+
+	return buf[:0]
+}
@@ -0,0 +1,190 @@
+package customerio_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func countItems(requests []batchRequest) int {
+	n := 0
+	for _, r := range requests {
+		n += len(r.Batch)
+	}
+	return n
+}
+
+func TestAsyncFlush(t *testing.T) {
+	track, srv, requests := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		w.Write([]byte(`{}`))
+	})
+	defer srv.Close()
+
+	async := customerio.NewAsyncTrackClient(track, customerio.AsyncConfig{
+		FlushInterval: time.Hour,
+		Workers:       2,
+	})
+	defer async.Close(context.Background())
+
+	for i := 0; i < 10; i++ {
+		if err := async.Track("1", "purchase", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := async.Identify("1", map[string]interface{}{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := async.AddDevice("1", "d1", "ios", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := async.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := countItems(*requests); got != 12 {
+		t.Errorf("wrong number of items sent. got: %d, want: %d", got, 12)
+	}
+}
+
+func TestAsyncBatchSizeAndInterval(t *testing.T) {
+	track, srv, requests := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		w.Write([]byte(`{}`))
+	})
+	defer srv.Close()
+
+	async := customerio.NewAsyncTrackClient(track, customerio.AsyncConfig{
+		BatchSize:     2,
+		FlushInterval: 10 * time.Millisecond,
+	})
+
+	for i := 0; i < 5; i++ {
+		if err := async.Delete("1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := async.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := countItems(*requests); got != 5 {
+		t.Errorf("wrong number of items sent. got: %d, want: %d", got, 5)
+	}
+	for _, r := range *requests {
+		if len(r.Batch) > 2 {
+			t.Errorf("batch larger than BatchSize: %d", len(r.Batch))
+		}
+	}
+
+	if err := async.Identify("1", nil); err != customerio.ErrAsyncClosed {
+		t.Errorf("expected ErrAsyncClosed, got: %#v", err)
+	}
+}
+
+func TestAsyncOnError(t *testing.T) {
+	track, srv, _ := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer srv.Close()
+
+	var (
+		mu     sync.Mutex
+		failed []customerio.BatchItem
+	)
+	async := customerio.NewAsyncTrackClient(track, customerio.AsyncConfig{
+		OnError: func(item customerio.BatchItem, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed = append(failed, item)
+		},
+	})
+
+	if err := async.Track("1", "purchase", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := async.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(failed) != 1 || failed[0].Name != "purchase" {
+		t.Errorf("expected failed purchase event, got: %#v", failed)
+	}
+}
+
+func TestAsyncParamsAndQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	track, srv, _ := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		<-block
+	})
+	defer srv.Close()
+
+	async := customerio.NewAsyncTrackClient(track, customerio.AsyncConfig{
+		QueueSize: 1,
+		BatchSize: 1,
+	})
+
+	checkParamError(t, async.Identify("", nil), "customerID")
+	checkParamError(t, async.Track("1", "", nil), "eventName")
+	checkParamError(t, async.AddDevice("1", "", "ios", nil), "deviceID")
+
+	var err error
+	for i := 0; i < 5 && err == nil; i++ {
+		err = async.Track("1", "purchase", nil)
+	}
+	if err != customerio.ErrQueueFull {
+		t.Errorf("expected ErrQueueFull, got: %#v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := async.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected flush to time out, got: %#v", err)
+	}
+
+	close(block)
+	if err := async.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsyncCloseTimeout(t *testing.T) {
+	block := make(chan struct{})
+	track, srv, _ := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		<-block
+	})
+	defer srv.Close()
+	defer close(block)
+
+	errs := make(chan error, 1)
+	async := customerio.NewAsyncTrackClient(track, customerio.AsyncConfig{
+		BatchSize: 1,
+		OnError: func(item customerio.BatchItem, err error) {
+			errs <- err
+		},
+	})
+
+	if err := async.Track("1", "purchase", nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := async.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected close to time out, got: %#v", err)
+	}
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the send to be canceled, got: %#v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the send in progress to be canceled")
+	}
+}
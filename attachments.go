@@ -0,0 +1,303 @@
+package customerio
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrAttachmentExists = errors.New("attachment with this name already exists")
+
+// ErrAttachmentTooLarge is returned when adding an attachment would exceed MaxAttachmentsSize.
+var ErrAttachmentTooLarge = errors.New("attachments exceed the maximum size")
+
+// ErrTooManyAttachments is returned when adding an attachment would exceed MaxAttachments.
+var ErrTooManyAttachments = errors.New("too many attachments")
+
+const (
+	// MaxAttachmentsSize is the maximum total size in bytes of the attachments of an email, before encoding.
+	MaxAttachmentsSize = 2 * 1024 * 1024
+	// MaxAttachments is the maximum number of attachments of an email.
+	MaxAttachments = 100
+)
+
+// fileAttachment is a file attached with AttachFile. It is read and encoded
+// only when the request is sent.
+type fileAttachment struct {
+	name string
+	path string
+	size int64
+}
+
+// preferredExtensions picks the usual extension for common types, which
+// mime.ExtensionsByType may return among less common ones.
+var preferredExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"application/zip": ".zip",
+	"image/gif":       ".gif",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"text/csv":        ".csv",
+	"text/html":       ".html",
+	"text/plain":      ".txt",
+	"text/xml":        ".xml",
+}
+
+// Attach base64 encodes the content of value and attaches it to the email under name.
+// It returns ErrAttachmentTooLarge, without reading further, once the attachments
+// of the request would exceed MaxAttachmentsSize, and ErrTooManyAttachments when the
+// request already has MaxAttachments attachments.
+func (e *SendEmailRequest) Attach(name string, value io.Reader) error {
+	if e.hasAttachment(name) {
+		return ErrAttachmentExists
+	}
+	if e.attachmentCount() >= MaxAttachments {
+		return ErrTooManyAttachments
+	}
+
+	remaining := int64(MaxAttachmentsSize - e.attachmentsSize())
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	var buf strings.Builder
+	enc := base64.NewEncoder(base64.StdEncoding, &buf)
+	n, err := io.Copy(enc, io.LimitReader(value, remaining+1))
+	if err != nil {
+		return err
+	}
+	if n > remaining {
+		return ErrAttachmentTooLarge
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if e.Attachments == nil {
+		e.Attachments = map[string]string{}
+	}
+	e.Attachments[name] = buf.String()
+	return nil
+}
+
+// AttachFile attaches the file at path to the email. The file is named after the
+// base of path; when that has no extension, one is added from the detected MIME type
+// so the recipient's mail client can open it.
+//
+// The file is not read into memory: it is encoded while the request is sent, so it
+// must remain readable until then.
+func (e *SendEmailRequest) AttachFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+
+	name := filepath.Base(path)
+	if filepath.Ext(name) == "" {
+		ext, err := detectExtension(f)
+		if err != nil {
+			return err
+		}
+		name += ext
+	}
+
+	if e.hasAttachment(name) {
+		return ErrAttachmentExists
+	}
+	if e.attachmentCount() >= MaxAttachments {
+		return ErrTooManyAttachments
+	}
+	if int64(e.attachmentsSize())+info.Size() > MaxAttachmentsSize {
+		return ErrAttachmentTooLarge
+	}
+
+	e.files = append(e.files, fileAttachment{name: name, path: path, size: info.Size()})
+	return nil
+}
+
+// detectExtension sniffs the MIME type of r and returns a matching file extension, if any.
+func detectExtension(r io.Reader) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	typ, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return "", nil
+	}
+	if ext, ok := preferredExtensions[typ]; ok {
+		return ext, nil
+	}
+	if exts, _ := mime.ExtensionsByType(typ); len(exts) > 0 {
+		return exts[0], nil
+	}
+	return "", nil
+}
+
+func (e *SendEmailRequest) hasAttachment(name string) bool {
+	if _, ok := e.Attachments[name]; ok {
+		return true
+	}
+	for _, f := range e.files {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// attachmentSizes returns the decoded size of every attachment, by name.
+func (e *SendEmailRequest) attachmentSizes() map[string]int {
+	sizes := make(map[string]int, len(e.Attachments)+len(e.files))
+	for name, content := range e.Attachments {
+		sizes[name] = decodedLen(content)
+	}
+	for _, f := range e.files {
+		sizes[f.name] = int(f.size)
+	}
+	return sizes
+}
+
+// attachmentCount returns the number of attachments, including the files attached with AttachFile.
+func (e *SendEmailRequest) attachmentCount() int {
+	return len(e.attachmentSizes())
+}
+
+func (e *SendEmailRequest) attachmentsSize() int {
+	total := 0
+	for _, n := range e.attachmentSizes() {
+		total += n
+	}
+	return total
+}
+
+// decodedLen returns the size of base64 encoded content once decoded.
+func decodedLen(content string) int {
+	n := base64.StdEncoding.DecodedLen(len(content))
+	return n - (len(content) - len(strings.TrimRight(content, "=")))
+}
+
+// sendEmailRequest has the fields of SendEmailRequest without its MarshalJSON method.
+type sendEmailRequest SendEmailRequest
+
+// MarshalJSON encodes the request, including the files attached with AttachFile.
+func (e *SendEmailRequest) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := e.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSON streams the JSON encoding of the request to w, encoding attachments
+// as they are written so that files are never held in memory.
+func (e *SendEmailRequest) writeJSON(w io.Writer) error {
+	base := *e
+	base.Attachments = nil
+	b, err := json.Marshal((*sendEmailRequest)(&base))
+	if err != nil {
+		return err
+	}
+	if len(e.Attachments) == 0 && len(e.files) == 0 {
+		_, err := w.Write(b)
+		return err
+	}
+
+	// re-open the object to append the attachments to it.
+	b = b[:len(b)-1]
+	if len(b) > 1 {
+		b = append(b, ',')
+	}
+	b = append(b, `"attachments":{`...)
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(e.Attachments))
+	for name := range e.Attachments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	first := true
+	entry := func(name string) error {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		if !first {
+			key = append([]byte{','}, key...)
+		}
+		first = false
+		_, err = w.Write(append(key, ':'))
+		return err
+	}
+
+	for _, name := range names {
+		if err := entry(name); err != nil {
+			return err
+		}
+		value, err := json.Marshal(e.Attachments[name])
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(value); err != nil {
+			return err
+		}
+	}
+	for _, f := range e.files {
+		if err := entry(f.name); err != nil {
+			return err
+		}
+		if err := f.writeJSON(w); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "}}")
+	return err
+}
+
+// writeJSON writes the content of the file to w as a base64 encoded JSON string.
+// The base64 alphabet needs no escaping in JSON.
+func (f fileAttachment) writeJSON(w io.Writer) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.WriteString(w, `"`); err != nil {
+		return err
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := io.Copy(enc, io.LimitReader(file, f.size)); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `"`)
+	return err
+}
@@ -0,0 +1,230 @@
+package customerio_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestAttach(t *testing.T) {
+	req := &customerio.SendEmailRequest{}
+
+	if err := req.Attach("a.txt", strings.NewReader("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if expect := map[string]string{"a.txt": "aGVsbG8="}; !reflect.DeepEqual(req.Attachments, expect) {
+		t.Errorf("Expect: %v, Got: %v", expect, req.Attachments)
+	}
+	if err := req.Attach("a.txt", strings.NewReader("hello")); err != customerio.ErrAttachmentExists {
+		t.Errorf("Expected ErrAttachmentExists, got: %v", err)
+	}
+
+	big := bytes.NewReader(make([]byte, customerio.MaxAttachmentsSize))
+	if err := req.Attach("big.bin", big); err != customerio.ErrAttachmentTooLarge {
+		t.Errorf("Expected ErrAttachmentTooLarge, got: %v", err)
+	}
+	if _, ok := req.Attachments["big.bin"]; ok {
+		t.Error("Expected oversized attachment not to be added")
+	}
+}
+
+func TestAttachFile(t *testing.T) {
+	dir := t.TempDir()
+	pdf := []byte("%PDF-1.4\n%fake receipt\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "receipt"), pdf, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "big.bin"), make([]byte, customerio.MaxAttachmentsSize), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &customerio.SendEmailRequest{
+		Identifiers:            map[string]string{"id": "customer_1"},
+		To:                     "customer@example.com",
+		TransactionalMessageID: "1",
+	}
+	if err := req.Attach("inline.txt", strings.NewReader("inline")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"receipt", "notes.txt"} {
+		if err := req.AttachFile(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := req.AttachFile(filepath.Join(dir, "notes.txt")); err != customerio.ErrAttachmentExists {
+		t.Errorf("Expected ErrAttachmentExists, got: %v", err)
+	}
+	if err := req.AttachFile(filepath.Join(dir, "big.bin")); err != customerio.ErrAttachmentTooLarge {
+		t.Errorf("Expected ErrAttachmentTooLarge, got: %v", err)
+	}
+	if err := req.AttachFile(filepath.Join(dir, "missing.pdf")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	// the first attempt fails so the streamed body must be sent again.
+	var calls int32
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		bodies = append(bodies, b)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"delivery_id": "d1", "queued_at": 1}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithRetry(testRetryPolicy))
+	api.URL = srv.URL
+
+	if _, err := api.SendEmail(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 || !bytes.Equal(bodies[0], bodies[1]) {
+		t.Fatalf("Expected the same body to be sent twice, got: %q", bodies)
+	}
+
+	var sent customerio.SendEmailRequest
+	if err := json.Unmarshal(bodies[1], &sent); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{
+		"inline.txt":  base64.StdEncoding.EncodeToString([]byte("inline")),
+		"receipt.pdf": base64.StdEncoding.EncodeToString(pdf),
+		"notes.txt":   base64.StdEncoding.EncodeToString([]byte("notes")),
+	}
+	if !reflect.DeepEqual(sent.Attachments, expect) {
+		t.Errorf("Expect: %v, Got: %v", expect, sent.Attachments)
+	}
+	if sent.TransactionalMessageID != "1" || sent.To != "customer@example.com" {
+		t.Errorf("Request fields were not sent: %s", bodies[1])
+	}
+
+	marshaled, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(marshaled, bodies[1]) {
+		t.Errorf("Expected MarshalJSON to match the sent body, got: %s", marshaled)
+	}
+}
+
+func TestValidateAttachmentSize(t *testing.T) {
+	req := &customerio.SendEmailRequest{
+		Identifiers:            map[string]string{"id": "customer_1"},
+		TransactionalMessageID: "1",
+		Attachments: map[string]string{
+			"big.bin": base64.StdEncoding.EncodeToString(make([]byte, customerio.MaxAttachmentsSize+1)),
+		},
+	}
+
+	var verr *customerio.ValidationError
+	if err := req.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("Expected ValidationError, got: %v", err)
+	}
+	var fields []string
+	for _, fe := range verr.Errors {
+		fields = append(fields, fe.Field)
+	}
+	if expect := []string{"attachments.big.bin", "attachments"}; !reflect.DeepEqual(fields, expect) {
+		t.Errorf("Expect: %v, Got: %v", expect, fields)
+	}
+}
+
+func TestAttachFileCount(t *testing.T) {
+	dir := t.TempDir()
+	req := &customerio.SendEmailRequest{
+		Identifiers:            map[string]string{"id": "customer_1"},
+		TransactionalMessageID: "1",
+	}
+	for i := 0; i <= customerio.MaxAttachments; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%d.txt", i))
+		if err := ioutil.WriteFile(path, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		err := req.AttachFile(path)
+		if i < customerio.MaxAttachments && err != nil {
+			t.Fatal(err)
+		}
+		if i == customerio.MaxAttachments && err != customerio.ErrTooManyAttachments {
+			t.Errorf("Expected ErrTooManyAttachments, got: %v", err)
+		}
+	}
+	if err := req.Attach("inline.txt", strings.NewReader("x")); err != customerio.ErrTooManyAttachments {
+		t.Errorf("Expected ErrTooManyAttachments, got: %v", err)
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Expected %d attachments to be valid, got: %v", customerio.MaxAttachments, err)
+	}
+
+	// attachments set directly count along with the files.
+	req.Attachments = map[string]string{"inline.txt": "eA=="}
+	var verr *customerio.ValidationError
+	if err := req.Validate(); !errors.As(err, &verr) || len(verr.Errors) != 1 || verr.Errors[0].Field != "attachments" {
+		t.Errorf("Expected a ValidationError on attachments, got: %v", err)
+	}
+}
+
+func TestAttachFileMiddlewareShortCircuit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.bin")
+	if err := ioutil.WriteFile(path, make([]byte, 256*1024), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the middleware answers without sending the request, so its body is never read.
+	api := customerio.NewAPIClient("myKey", customerio.WithMiddleware(func(next customerio.RoundTripFunc) customerio.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"delivery_id": "d1"}`)),
+				Request:    req,
+			}, nil
+		}
+	}))
+	api.URL = "http://invalid"
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		req := &customerio.SendEmailRequest{
+			Identifiers:            map[string]string{"id": "customer_1"},
+			TransactionalMessageID: "1",
+		}
+		if err := req.AttachFile(path); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := api.SendEmail(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("Expected the body writers to stop, %d goroutines left over", n-before)
+	}
+}
@@ -0,0 +1,294 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// MaxBatchSize is the maximum size in bytes of a single v2 batch request.
+	MaxBatchSize = 500 * 1024
+	// MaxBatchItemSize is the maximum size in bytes of a single item of a v2 batch request.
+	MaxBatchItemSize = 32 * 1024
+)
+
+// batchEnvelopeSize is the length of `{"batch":[]}`.
+const batchEnvelopeSize = 12
+
+var ErrBatchItemTooLarge = errors.New("batch item exceeds the maximum item size")
+
+// BatchItem is a single operation sent to the v2 batch endpoint,
+// see: https://customer.io/docs/api/track/#operation/batch
+// Use the Batch* helpers to build items rather than filling the fields by hand.
+type BatchItem struct {
+	Type          string                 `json:"type"`
+	Action        string                 `json:"action"`
+	Identifiers   map[string]string      `json:"identifiers,omitempty"`
+	Name          string                 `json:"name,omitempty"`
+	Timestamp     int64                  `json:"timestamp,omitempty"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Device        *Device                `json:"device,omitempty"`
+	Primary       map[string]string      `json:"primary,omitempty"`
+	Secondary     map[string]string      `json:"secondary,omitempty"`
+	Relationships []Relationship         `json:"cio_relationships,omitempty"`
+}
+
+func personItem(action string, id Identifier) BatchItem {
+	return BatchItem{
+		Type:        "person",
+		Action:      action,
+		Identifiers: id.kv(),
+	}
+}
+
+// BatchIdentify creates or updates a person and sets their attributes.
+func BatchIdentify(id Identifier, attributes map[string]interface{}) BatchItem {
+	item := personItem("identify", id)
+	item.Attributes = attributes
+	return item
+}
+
+// BatchEvent sends an event for a person.
+func BatchEvent(id Identifier, eventName string, data map[string]interface{}) BatchItem {
+	item := personItem("event", id)
+	item.Name = eventName
+	item.Attributes = data
+	return item
+}
+
+// BatchDelete deletes a person.
+func BatchDelete(id Identifier) BatchItem {
+	return personItem("delete", id)
+}
+
+// BatchSuppress suppresses a person.
+func BatchSuppress(id Identifier) BatchItem {
+	return personItem("suppress", id)
+}
+
+// BatchUnsuppress unsuppresses a person.
+func BatchUnsuppress(id Identifier) BatchItem {
+	return personItem("unsuppress", id)
+}
+
+// BatchAddDevice adds or updates a device for a person.
+func BatchAddDevice(id Identifier, deviceID, platform string, data map[string]interface{}) BatchItem {
+	item := personItem("add_device", id)
+	d, err := NewDevice(deviceID, platform, data)
+	if err != nil {
+		// Keep the invalid device so that the item is rejected with err when sent.
+		d = &Device{ID: deviceID, Platform: platform}
+	}
+	item.Device = d
+	return item
+}
+
+// BatchDeleteDevice removes a device from a person.
+func BatchDeleteDevice(id Identifier, deviceID string) BatchItem {
+	item := personItem("delete_device", id)
+	item.Device = &Device{ID: deviceID}
+	return item
+}
+
+// BatchMerge merges the secondary person into the primary one.
+func BatchMerge(primary, secondary Identifier) BatchItem {
+	return BatchItem{
+		Type:      "person",
+		Action:    "merge",
+		Primary:   primary.kv(),
+		Secondary: secondary.kv(),
+	}
+}
+
+func (b BatchItem) validate() error {
+	switch b.Action {
+	case "merge":
+		if !validIdentifiers(b.Primary) {
+			return ParamError{Param: "primary"}
+		}
+		if !validIdentifiers(b.Secondary) {
+			return ParamError{Param: "secondary"}
+		}
+		return nil
+	case "":
+		return ParamError{Param: "action"}
+	}
+
+	if !validIdentifiers(b.Identifiers) {
+		return ParamError{Param: "identifiers"}
+	}
+
+	switch b.Action {
+	case "event":
+		if b.Name == "" {
+			return ParamError{Param: "eventName"}
+		}
+	case "add_device":
+		if b.Device == nil || b.Device.ID == "" {
+			return ParamError{Param: "deviceID"}
+		}
+		if b.Device.Platform == "" {
+			return ParamError{Param: "platform"}
+		}
+	case "delete_device":
+		if b.Device == nil || b.Device.ID == "" {
+			return ParamError{Param: "deviceID"}
+		}
+	case "add_relationships", "delete_relationships":
+		if len(b.Relationships) == 0 {
+			return ParamError{Param: "relationships"}
+		}
+		for _, r := range b.Relationships {
+			if r.ObjectType == "" || r.ObjectID == "" {
+				return ParamError{Param: "relationships"}
+			}
+		}
+	}
+	return nil
+}
+
+// validIdentifiers reports whether kv holds at least one identifier, with no empty key or value.
+func validIdentifiers(kv map[string]string) bool {
+	if len(kv) == 0 {
+		return false
+	}
+	for k, v := range kv {
+		if k == "" || v == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// BatchResult is the outcome of a single batch item.
+type BatchResult struct {
+	// Err is nil when the item was accepted.
+	Err error
+}
+
+// BatchItemError is returned for an item that Customer.io rejected.
+type BatchItemError struct {
+	Index   int    `json:"batch_index"`
+	Reason  string `json:"reason"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch item %d: %s: %s %s", e.Index, e.Reason, e.Field, e.Message)
+}
+
+// BatchError is returned by Batch, SendEmailBatch and SendPushBatch when at least one item failed.
+type BatchError struct {
+	Failed int
+	Total  int
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d batch items failed", e.Failed, e.Total)
+}
+
+// Batch sends several operations at once using the v2 batch endpoint.
+// Items are split across as many requests as needed to honor MaxBatchSize.
+// The returned results have the same length and order as items. When at least
+// one item failed, a *BatchError is returned and the failed results carry their error.
+func (c *CustomerIO) Batch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	if len(items) == 0 {
+		return nil, ParamError{Param: "items"}
+	}
+
+	results := make([]BatchResult, len(items))
+
+	var (
+		chunk   []int
+		encoded = make([][]byte, len(items))
+		size    = batchEnvelopeSize
+	)
+	flush := func() {
+		if len(chunk) > 0 {
+			c.sendBatch(ctx, chunk, encoded, results)
+		}
+		chunk = chunk[:0]
+		size = batchEnvelopeSize
+	}
+
+	for i, item := range items {
+		if err := item.validate(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		b, err := json.Marshal(item)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if len(b) > MaxBatchItemSize {
+			results[i].Err = ErrBatchItemTooLarge
+			continue
+		}
+		encoded[i] = b
+
+		extra := len(b)
+		if len(chunk) > 0 {
+			extra++ // separating comma
+		}
+		if size+extra > MaxBatchSize {
+			flush()
+			extra = len(b)
+		}
+		chunk = append(chunk, i)
+		size += extra
+	}
+	flush()
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, &BatchError{Failed: failed, Total: len(items)}
+	}
+	return results, nil
+}
+
+// sendBatch posts the items at the given indexes and records their results.
+func (c *CustomerIO) sendBatch(ctx context.Context, indexes []int, encoded [][]byte, results []BatchResult) {
+	items := make([]json.RawMessage, len(indexes))
+	for i, idx := range indexes {
+		items[i] = encoded[idx]
+	}
+
+	url := fmt.Sprintf("%s/api/v2/batch", c.URL)
+	resp, body, err := c.send(ctx, "POST", url, map[string]interface{}{
+		"batch": items,
+	})
+	if err == nil && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		err = newCustomerIOError("POST", url, resp, body)
+	}
+	if err != nil {
+		for _, idx := range indexes {
+			results[idx].Err = err
+		}
+		return
+	}
+
+	var partial struct {
+		Errors []*BatchItemError `json:"errors"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &partial) != nil {
+		return
+	}
+	for _, e := range partial.Errors {
+		if e.Index < 0 || e.Index >= len(indexes) {
+			continue
+		}
+		// report the index as seen by the caller rather than within the request.
+		e.Index = indexes[e.Index]
+		results[e.Index].Err = e
+	}
+}
@@ -0,0 +1,229 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+type batchRequest struct {
+	Batch []map[string]interface{} `json:"batch"`
+}
+
+func batchServer(t *testing.T, respond func(w http.ResponseWriter, req batchRequest)) (*customerio.CustomerIO, *httptest.Server, *[]batchRequest) {
+	var (
+		mu       sync.Mutex
+		requests []batchRequest
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" || req.URL.Path != "/api/v2/batch" {
+			t.Errorf("Unexpected request: %s %s", req.Method, req.URL.Path)
+		}
+		var body batchRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		requests = append(requests, body)
+		mu.Unlock()
+		respond(w, body)
+	}))
+
+	track := customerio.NewTrackClient("site_id", "api_key")
+	track.URL = srv.URL
+
+	return track, srv, &requests
+}
+
+func TestBatch(t *testing.T) {
+	track, srv, requests := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		w.Write([]byte(`{}`))
+	})
+	defer srv.Close()
+
+	id := customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}
+	results, err := track.Batch(context.Background(), []customerio.BatchItem{
+		customerio.BatchIdentify(id, map[string]interface{}{"plan": "basic"}),
+		customerio.BatchEvent(id, "purchase", map[string]interface{}{"price": 10}),
+		customerio.BatchAddDevice(id, "d1", "ios", nil),
+		customerio.BatchMerge(id, customerio.Identifier{Type: customerio.IdentifierTypeEmail, Value: "a@example.com"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("wrong number of results. got: %d, want: %d", len(results), 4)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("wrong number of requests. got: %d, want: %d", len(*requests), 1)
+	}
+	got := (*requests)[0].Batch
+	expect := []map[string]interface{}{
+		{"type": "person", "action": "identify", "identifiers": map[string]interface{}{"id": "1"}, "attributes": map[string]interface{}{"plan": "basic"}},
+		{"type": "person", "action": "event", "identifiers": map[string]interface{}{"id": "1"}, "name": "purchase", "attributes": map[string]interface{}{"price": float64(10)}},
+		{"type": "person", "action": "add_device", "identifiers": map[string]interface{}{"id": "1"}, "device": map[string]interface{}{"token": "d1", "platform": "ios"}},
+		{"type": "person", "action": "merge", "primary": map[string]interface{}{"id": "1"}, "secondary": map[string]interface{}{"email": "a@example.com"}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, got)
+	}
+}
+
+func TestBatchPartialFailure(t *testing.T) {
+	track, srv, _ := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write([]byte(`{"errors": [{"batch_index": 1, "reason": "invalid", "field": "name", "message": "is required"}]}`))
+	})
+	defer srv.Close()
+
+	id := customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}
+	results, err := track.Batch(context.Background(), []customerio.BatchItem{
+		customerio.BatchDelete(id),
+		customerio.BatchEvent(customerio.Identifier{}, "", nil),
+		customerio.BatchSuppress(id),
+		customerio.BatchUnsuppress(id),
+	})
+
+	var batchErr *customerio.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected BatchError, got: %#v", err)
+	}
+	if batchErr.Failed != 2 || batchErr.Total != 4 {
+		t.Errorf("wrong failure count: %#v", batchErr)
+	}
+
+	if _, ok := results[1].Err.(customerio.ParamError); !ok {
+		t.Errorf("expected ParamError for invalid item, got: %#v", results[1].Err)
+	}
+
+	// item 2 is the second item of the request, so index 1 as seen by the server.
+	itemErr, ok := results[2].Err.(*customerio.BatchItemError)
+	if !ok {
+		t.Fatalf("expected BatchItemError, got: %#v", results[2].Err)
+	}
+	if itemErr.Index != 2 {
+		t.Errorf("wrong index. got: %d, want: %d", itemErr.Index, 2)
+	}
+	if results[0].Err != nil || results[3].Err != nil {
+		t.Errorf("unexpected errors: %#v", results)
+	}
+}
+
+func TestBatchMergeValidation(t *testing.T) {
+	track, srv, requests := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		w.Write([]byte(`{}`))
+	})
+	defer srv.Close()
+
+	id := customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}
+	results, err := track.Batch(context.Background(), []customerio.BatchItem{
+		customerio.BatchMerge(customerio.Identifier{}, id),
+		customerio.BatchMerge(id, customerio.Identifier{Type: customerio.IdentifierTypeEmail}),
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for i, param := range []string{"primary", "secondary"} {
+		if pe, ok := results[i].Err.(customerio.ParamError); !ok || pe.Param != param {
+			t.Errorf("expected ParamError for %s, got: %#v", param, results[i].Err)
+		}
+	}
+	if len(*requests) != 0 {
+		t.Errorf("expected invalid merges not to be sent, got: %+v", *requests)
+	}
+}
+
+func TestBatchDeviceValidation(t *testing.T) {
+	track, srv, requests := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		w.Write([]byte(`{}`))
+	})
+	defer srv.Close()
+
+	id := customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}
+	results, err := track.Batch(context.Background(), []customerio.BatchItem{
+		customerio.BatchAddDevice(id, "", "ios", map[string]interface{}{"app_version": "1.0"}),
+		customerio.BatchAddDevice(id, "d1", "", nil),
+		customerio.BatchDeleteDevice(id, ""),
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for i, param := range []string{"deviceID", "platform", "deviceID"} {
+		if pe, ok := results[i].Err.(customerio.ParamError); !ok || pe.Param != param {
+			t.Errorf("expected ParamError for %s, got: %#v", param, results[i].Err)
+		}
+	}
+	if len(*requests) != 0 {
+		t.Errorf("expected invalid devices not to be sent, got: %+v", *requests)
+	}
+}
+
+func TestBatchSplit(t *testing.T) {
+	track, srv, requests := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		w.Write([]byte(`{}`))
+	})
+	defer srv.Close()
+
+	large := strings.Repeat("x", 30*1024)
+	items := make([]customerio.BatchItem, 40)
+	for i := range items {
+		items[i] = customerio.BatchIdentify(customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}, map[string]interface{}{
+			"bio": large,
+		})
+	}
+	items = append(items, customerio.BatchIdentify(customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}, map[string]interface{}{
+		"bio": large + large,
+	}))
+
+	results, err := track.Batch(context.Background(), items)
+	if err == nil {
+		t.Fatal("expected error for oversized item")
+	}
+	if results[40].Err != customerio.ErrBatchItemTooLarge {
+		t.Errorf("expected ErrBatchItemTooLarge, got: %#v", results[40].Err)
+	}
+
+	if len(*requests) < 3 {
+		t.Fatalf("expected batch to be split, got %d requests", len(*requests))
+	}
+	total := 0
+	for _, r := range *requests {
+		b, _ := json.Marshal(r)
+		if len(b) > customerio.MaxBatchSize {
+			t.Errorf("request too large: %d bytes", len(b))
+		}
+		total += len(r.Batch)
+	}
+	if total != 40 {
+		t.Errorf("wrong number of items sent. got: %d, want: %d", total, 40)
+	}
+}
+
+func TestBatchRequestError(t *testing.T) {
+	track, srv, _ := batchServer(t, func(w http.ResponseWriter, req batchRequest) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	defer srv.Close()
+
+	results, err := track.Batch(context.Background(), []customerio.BatchItem{
+		customerio.BatchDelete(customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}),
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if _, ok := results[0].Err.(*customerio.CustomerIOError); !ok {
+		t.Errorf("expected CustomerIOError, got: %#v", results[0].Err)
+	}
+
+	_, err = track.Batch(context.Background(), nil)
+	checkParamError(t, err, "items")
+}
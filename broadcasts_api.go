@@ -0,0 +1,162 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrMultipleBroadcastAudiences = errors.New("only one of IDs, Emails, PerUserData, Recipients and DataFileURL can be set")
+
+// BroadcastFilter describes the audience of a broadcast, see:
+// https://customer.io/docs/api/app/#operation/triggerBroadcast
+// Build filters with BroadcastSegment, BroadcastAttribute, BroadcastAnd, BroadcastOr and BroadcastNot.
+type BroadcastFilter map[string]interface{}
+
+// BroadcastSegment matches the people in a segment.
+func BroadcastSegment(segmentID int) BroadcastFilter {
+	return BroadcastFilter{"segment": map[string]int{"id": segmentID}}
+}
+
+// BroadcastAttribute matches the people whose attribute field compares to value using operator, e.g. "eq" or "exists".
+func BroadcastAttribute(field, operator string, value interface{}) BroadcastFilter {
+	attr := map[string]interface{}{
+		"field":    field,
+		"operator": operator,
+	}
+	if value != nil {
+		attr["value"] = value
+	}
+	return BroadcastFilter{"attribute": attr}
+}
+
+// BroadcastAnd matches the people matching all the filters.
+func BroadcastAnd(filters ...BroadcastFilter) BroadcastFilter {
+	return BroadcastFilter{"and": filters}
+}
+
+// BroadcastOr matches the people matching any of the filters.
+func BroadcastOr(filters ...BroadcastFilter) BroadcastFilter {
+	return BroadcastFilter{"or": filters}
+}
+
+// BroadcastNot matches the people not matching the filter.
+func BroadcastNot(filter BroadcastFilter) BroadcastFilter {
+	return BroadcastFilter{"not": filter}
+}
+
+// BroadcastRecipient is a recipient of a broadcast along with data specific to them.
+type BroadcastRecipient struct {
+	ID    string                 `json:"id,omitempty"`    // ID of the recipient, unless Email is set.
+	Email string                 `json:"email,omitempty"` // Email of the recipient, unless ID is set.
+	Data  map[string]interface{} `json:"data,omitempty"`  // Data available to the message as trigger.<key>.
+}
+
+// TriggerBroadcastRequest represents the payload to trigger a broadcast.
+// At most one of IDs, Emails, PerUserData, Recipients and DataFileURL can be set;
+// when none is, the audience defined in the broadcast is used.
+type TriggerBroadcastRequest struct {
+	Data               map[string]interface{} `json:"data,omitempty"`                 // Data available to every message as trigger.<key>.
+	IDs                []string               `json:"ids,omitempty"`                  // IDs of the recipients.
+	Emails             []string               `json:"emails,omitempty"`               // Emails of the recipients.
+	PerUserData        []BroadcastRecipient   `json:"per_user_data,omitempty"`        // Recipients with their own data.
+	Recipients         BroadcastFilter        `json:"recipients,omitempty"`           // Filter selecting the recipients.
+	DataFileURL        string                 `json:"data_file_url,omitempty"`        // URL of a JSON lines file of recipients with their data.
+	IDIgnoreMissing    bool                   `json:"id_ignore_missing,omitempty"`    // Skip IDs not matching a person instead of failing.
+	EmailIgnoreMissing bool                   `json:"email_ignore_missing,omitempty"` // Skip emails not matching a person instead of failing.
+	EmailAddDuplicates bool                   `json:"email_add_duplicates,omitempty"` // Send to every person sharing an email address.
+}
+
+func (r *TriggerBroadcastRequest) validate() error {
+	audiences := 0
+	for _, set := range []bool{
+		len(r.IDs) > 0,
+		len(r.Emails) > 0,
+		len(r.PerUserData) > 0,
+		len(r.Recipients) > 0,
+		r.DataFileURL != "",
+	} {
+		if set {
+			audiences++
+		}
+	}
+	if audiences > 1 {
+		return ErrMultipleBroadcastAudiences
+	}
+	return nil
+}
+
+// TriggerBroadcastResponse represents the response for triggering a broadcast.
+type TriggerBroadcastResponse struct {
+	ID int `json:"id"` // ID of the trigger, used to check its status.
+}
+
+// TriggerBroadcast triggers an API-triggered broadcast and returns the ID of the trigger.
+func (c *APIClient) TriggerBroadcast(ctx context.Context, broadcastID int, req *TriggerBroadcastRequest) (*TriggerBroadcastResponse, error) {
+	if req == nil {
+		req = &TriggerBroadcastRequest{}
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/v1/campaigns/%d/triggers", broadcastID)
+	resp, respBody, err := c.doRequest(ctx, "POST", path, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("POST", path, resp, respBody)
+	}
+
+	var response TriggerBroadcastResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// BroadcastTrigger represents the status of a broadcast trigger.
+type BroadcastTrigger struct {
+	ID        int   `json:"id"`         // Unique identifier for the trigger.
+	CreatedAt int64 `json:"created_at"` // Unix timestamp of the trigger.
+	Processed bool  `json:"processed"`  // Whether every recipient has been processed.
+}
+
+// GetBroadcastTriggerStatusResponse represents the response for retrieving the status of a broadcast trigger.
+type GetBroadcastTriggerStatusResponse struct {
+	Trigger BroadcastTrigger `json:"trigger"` // The requested trigger.
+}
+
+// GetBroadcastTriggerStatus returns the status of a broadcast trigger.
+func (c *APIClient) GetBroadcastTriggerStatus(ctx context.Context, broadcastID, triggerID int) (*GetBroadcastTriggerStatusResponse, error) {
+	var response GetBroadcastTriggerStatusResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/v1/campaigns/%d/triggers/%d", broadcastID, triggerID), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// BroadcastTriggerError describes a recipient that could not be processed by a broadcast trigger.
+type BroadcastTriggerError struct {
+	Reason string                 `json:"reason"` // Why the recipient was not processed.
+	Data   map[string]interface{} `json:"data"`   // The recipient data as provided in the trigger.
+}
+
+// ListBroadcastTriggerErrorsResponse represents a page of the errors of a broadcast trigger.
+type ListBroadcastTriggerErrorsResponse struct {
+	Errors []BroadcastTriggerError `json:"errors"` // List of errors.
+	Next   string                  `json:"next"`   // Optional pagination cursor.
+}
+
+// ListBroadcastTriggerErrors returns a page of the errors of a broadcast trigger. Use PageStart and PageLimit to paginate.
+func (c *APIClient) ListBroadcastTriggerErrors(ctx context.Context, broadcastID, triggerID int, opts ...ListOption) (*ListBroadcastTriggerErrorsResponse, error) {
+	var response ListBroadcastTriggerErrorsResponse
+	path := withQuery(fmt.Sprintf("/v1/campaigns/%d/triggers/%d/errors", broadcastID, triggerID), listQuery(nil, opts))
+	if err := c.getJSON(ctx, path, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
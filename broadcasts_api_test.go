@@ -0,0 +1,132 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func broadcastsAppServer(t *testing.T, verify func(request []byte)) (*customerio.APIClient, *httptest.Server) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		defer req.Body.Close()
+
+		switch {
+		case req.Method == "POST" && req.URL.Path == "/v1/campaigns/1/triggers":
+			verify(b)
+			w.Write([]byte(`{"id": 42}`))
+		case req.Method == "GET" && req.URL.Path == "/v1/campaigns/1/triggers/42":
+			w.Write([]byte(`{"trigger": {"id": 42, "created_at": 1600000000, "processed": true}}`))
+		case req.Method == "GET" && req.URL.RequestURI() == "/v1/campaigns/1/triggers/42/errors?limit=1&start=abc":
+			w.Write([]byte(`{"errors": [{"reason": "missing id", "data": {"email": "a@example.com"}}], "next": "def"}`))
+		default:
+			t.Errorf("Unexpected request: %s %s", req.Method, req.URL.RequestURI())
+		}
+	}))
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	return api, srv
+}
+
+func TestTriggerBroadcast(t *testing.T) {
+	cases := []struct {
+		req    *customerio.TriggerBroadcastRequest
+		expect string
+	}{
+		{
+			&customerio.TriggerBroadcastRequest{IDs: []string{"1", "2"}, Data: map[string]interface{}{"sale": "50%"}, IDIgnoreMissing: true},
+			`{"data":{"sale":"50%"},"ids":["1","2"],"id_ignore_missing":true}`,
+		},
+		{
+			&customerio.TriggerBroadcastRequest{PerUserData: []customerio.BroadcastRecipient{{Email: "a@example.com", Data: map[string]interface{}{"n": 1}}}},
+			`{"per_user_data":[{"email":"a@example.com","data":{"n":1}}]}`,
+		},
+		{
+			&customerio.TriggerBroadcastRequest{Recipients: customerio.BroadcastAnd(
+				customerio.BroadcastSegment(7),
+				customerio.BroadcastNot(customerio.BroadcastAttribute("plan", "eq", "free")),
+			)},
+			`{"recipients":{"and":[{"segment":{"id":7}},{"not":{"attribute":{"field":"plan","operator":"eq","value":"free"}}}]}}`,
+		},
+		{
+			&customerio.TriggerBroadcastRequest{DataFileURL: "https://example.com/recipients.json"},
+			`{"data_file_url":"https://example.com/recipients.json"}`,
+		},
+		{
+			nil,
+			`{}`,
+		},
+	}
+
+	for _, c := range cases {
+		var verify = func(request []byte) {
+			var got, want interface{}
+			if err := json.Unmarshal(request, &got); err != nil {
+				t.Error(err)
+			}
+			json.Unmarshal([]byte(c.expect), &want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Request differed, want: %s, got: %s", c.expect, request)
+			}
+		}
+
+		api, srv := broadcastsAppServer(t, verify)
+		resp, err := api.TriggerBroadcast(context.Background(), 1, c.req)
+		if err != nil {
+			t.Error(err)
+		} else if resp.ID != 42 {
+			t.Errorf("wrong trigger id. got: %d, want: %d", resp.ID, 42)
+		}
+		srv.Close()
+	}
+}
+
+func TestTriggerBroadcastMultipleAudiences(t *testing.T) {
+	api := customerio.NewAPIClient("myKey")
+	_, err := api.TriggerBroadcast(context.Background(), 1, &customerio.TriggerBroadcastRequest{
+		IDs:    []string{"1"},
+		Emails: []string{"a@example.com"},
+	})
+	if err != customerio.ErrMultipleBroadcastAudiences {
+		t.Errorf("Expected ErrMultipleBroadcastAudiences, got: %#v", err)
+	}
+}
+
+func TestBroadcastTriggerStatusAndErrors(t *testing.T) {
+	api, srv := broadcastsAppServer(t, func([]byte) {})
+	defer srv.Close()
+
+	status, err := api.GetBroadcastTriggerStatus(context.Background(), 1, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus := &customerio.GetBroadcastTriggerStatusResponse{
+		Trigger: customerio.BroadcastTrigger{ID: 42, CreatedAt: 1600000000, Processed: true},
+	}
+	if !reflect.DeepEqual(status, expectStatus) {
+		t.Errorf("Expect: %#v, Got: %#v", expectStatus, status)
+	}
+
+	errs, err := api.ListBroadcastTriggerErrors(context.Background(), 1, 42, customerio.PageStart("abc"), customerio.PageLimit(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectErrs := &customerio.ListBroadcastTriggerErrorsResponse{
+		Errors: []customerio.BroadcastTriggerError{{Reason: "missing id", Data: map[string]interface{}{"email": "a@example.com"}}},
+		Next:   "def",
+	}
+	if !reflect.DeepEqual(errs, expectErrs) {
+		t.Errorf("Expect: %#v, Got: %#v", expectErrs, errs)
+	}
+}
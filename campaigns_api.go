@@ -0,0 +1,189 @@
+package customerio
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Campaign represents a campaign object returned by the API.
+type Campaign struct {
+	ID                int              `json:"id"`                  // Unique identifier for the campaign.
+	DeduplicateID     string           `json:"deduplicate_id"`      // A string in the format id:timestamp.
+	Name              string           `json:"name"`                // Name of the campaign.
+	Type              string           `json:"type"`                // What triggers the campaign, e.g. segment or event.
+	State             string           `json:"state"`               // State of the campaign: draft, running, stopped...
+	Active            bool             `json:"active"`              // Whether the campaign is currently running.
+	Created           int64            `json:"created"`             // Unix timestamp of the campaign creation.
+	Updated           int64            `json:"updated"`             // Unix timestamp of the last update.
+	FirstStarted      int64            `json:"first_started"`       // Unix timestamp of the first time the campaign started.
+	CreatedBy         string           `json:"created_by"`          // Who created the campaign.
+	Tags              []string         `json:"tags,omitempty"`      // Optional tags associated with the campaign.
+	Actions           []CampaignAction `json:"actions,omitempty"`   // Summary of the actions of the campaign.
+	TriggerSegmentIDs []int            `json:"trigger_segment_ids"` // Segments triggering the campaign.
+	FilterSegmentIDs  []int            `json:"filter_segment_ids"`  // Segments filtering the campaign audience.
+	EventName         string           `json:"event_name"`          // Event triggering the campaign, for event campaigns.
+}
+
+// CampaignAction represents a step of a campaign, such as a message or a delay.
+type CampaignAction struct {
+	ID             int    `json:"id"`               // Unique identifier for the action.
+	CampaignID     int    `json:"campaign_id"`      // Campaign the action belongs to.
+	ParentActionID int    `json:"parent_action_id"` // Action this one is a language variant of, if any.
+	DeduplicateID  string `json:"deduplicate_id"`   // A string in the format id:timestamp.
+	Name           string `json:"name"`             // Name of the action.
+	Type           string `json:"type"`             // Type of the action, e.g. email, push or webhook.
+	Language       string `json:"language"`         // Language of the variant, if any.
+	Layout         string `json:"layout"`           // Layout used by the message, for emails.
+	Created        int64  `json:"created"`          // Unix timestamp of the action creation.
+	Updated        int64  `json:"updated"`          // Unix timestamp of the last update.
+	From           string `json:"from"`             // Sender of the message.
+	ReplyTo        string `json:"reply_to"`         // Reply-to address of the message.
+	Recipient      string `json:"recipient"`        // Recipient of the message.
+	Subject        string `json:"subject"`          // Subject of the message.
+	Body           string `json:"body"`             // Body of the message.
+}
+
+// MetricsPeriod is the unit of time of each step of a metric series.
+type MetricsPeriod string
+
+const (
+	MetricsPeriodHours  MetricsPeriod = "hours"
+	MetricsPeriodDays   MetricsPeriod = "days"
+	MetricsPeriodWeeks  MetricsPeriod = "weeks"
+	MetricsPeriodMonths MetricsPeriod = "months"
+)
+
+// MetricsRequest selects the metrics to return. Zero values use the API defaults.
+type MetricsRequest struct {
+	Period MetricsPeriod // Unit of time of each step.
+	Steps  int           // Number of periods to return.
+	Type   string        // Only count messages of this type, e.g. email or push.
+	Unique bool          // Only count unique clicks, for link metrics.
+}
+
+func (r *MetricsRequest) query() url.Values {
+	q := url.Values{}
+	if r == nil {
+		return q
+	}
+	if r.Period != "" {
+		q.Set("period", string(r.Period))
+	}
+	if r.Steps > 0 {
+		q.Set("steps", strconv.Itoa(r.Steps))
+	}
+	if r.Type != "" {
+		q.Set("type", r.Type)
+	}
+	if r.Unique {
+		q.Set("unique", "true")
+	}
+	return q
+}
+
+// MetricSeries holds one count per period for each metric, oldest first.
+type MetricSeries struct {
+	Attempted     []int `json:"attempted"`
+	Bounced       []int `json:"bounced"`
+	Clicked       []int `json:"clicked"`
+	Converted     []int `json:"converted"`
+	Created       []int `json:"created"`
+	Delivered     []int `json:"delivered"`
+	Drafted       []int `json:"drafted"`
+	Failed        []int `json:"failed"`
+	Opened        []int `json:"opened"`
+	Sent          []int `json:"sent"`
+	Spammed       []int `json:"spammed"`
+	Undeliverable []int `json:"undeliverable"`
+	Unsubscribed  []int `json:"unsubscribed"`
+}
+
+// Metrics represents the metrics of a campaign, action or newsletter.
+type Metrics struct {
+	Series MetricSeries `json:"series"` // Counts per period.
+}
+
+// GetMetricsResponse represents the response for retrieving metrics.
+type GetMetricsResponse struct {
+	Metric Metrics `json:"metric"` // The requested metrics.
+}
+
+// ListCampaignsResponse represents the response containing multiple campaigns.
+type ListCampaignsResponse struct {
+	Campaigns []Campaign `json:"campaigns"` // List of campaigns.
+}
+
+// ListCampaigns retrieves all campaigns from the API.
+func (c *APIClient) ListCampaigns(ctx context.Context) (*ListCampaignsResponse, error) {
+	var response ListCampaignsResponse
+	if err := c.getJSON(ctx, "/v1/campaigns", &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetCampaignResponse represents the response for retrieving a single campaign.
+type GetCampaignResponse struct {
+	Campaign Campaign `json:"campaign"` // The requested campaign.
+}
+
+// GetCampaign retrieves a specific campaign by its ID.
+func (c *APIClient) GetCampaign(ctx context.Context, campaignID int) (*GetCampaignResponse, error) {
+	var response GetCampaignResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/v1/campaigns/%d", campaignID), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ListCampaignActionsResponse represents a page of the actions of a campaign.
+type ListCampaignActionsResponse struct {
+	Actions []CampaignAction `json:"actions"` // List of actions.
+	Next    string           `json:"next"`    // Optional pagination cursor.
+}
+
+// ListCampaignActions retrieves a page of the actions of a campaign. Use PageStart and PageLimit to paginate.
+func (c *APIClient) ListCampaignActions(ctx context.Context, campaignID int, opts ...ListOption) (*ListCampaignActionsResponse, error) {
+	var response ListCampaignActionsResponse
+	path := withQuery(fmt.Sprintf("/v1/campaigns/%d/actions", campaignID), listQuery(nil, opts))
+	if err := c.getJSON(ctx, path, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetCampaignActionResponse represents the response for retrieving a single campaign action.
+type GetCampaignActionResponse struct {
+	Action CampaignAction `json:"action"` // The requested action.
+}
+
+// GetCampaignAction retrieves a specific action of a campaign.
+func (c *APIClient) GetCampaignAction(ctx context.Context, campaignID, actionID int) (*GetCampaignActionResponse, error) {
+	var response GetCampaignActionResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/v1/campaigns/%d/actions/%d", campaignID, actionID), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetCampaignMetrics returns the metrics of a campaign over time. req may be nil to use the API defaults.
+func (c *APIClient) GetCampaignMetrics(ctx context.Context, campaignID int, req *MetricsRequest) (*GetMetricsResponse, error) {
+	var response GetMetricsResponse
+	path := withQuery(fmt.Sprintf("/v1/campaigns/%d/metrics", campaignID), req.query())
+	if err := c.getJSON(ctx, path, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetCampaignActionMetrics returns the metrics of a campaign action over time. req may be nil to use the API defaults.
+func (c *APIClient) GetCampaignActionMetrics(ctx context.Context, campaignID, actionID int, req *MetricsRequest) (*GetMetricsResponse, error) {
+	var response GetMetricsResponse
+	path := withQuery(fmt.Sprintf("/v1/campaigns/%d/actions/%d/metrics", campaignID, actionID), req.query())
+	if err := c.getJSON(ctx, path, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
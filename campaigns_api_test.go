@@ -0,0 +1,144 @@
+package customerio_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func campaignsAppServer(t *testing.T) (*customerio.APIClient, *httptest.Server) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "GET" {
+			t.Errorf("Unexpected method: %s", req.Method)
+		}
+
+		switch req.URL.RequestURI() {
+		case "/v1/campaigns":
+			w.Write([]byte(`{"campaigns": [{"id": 1, "name": "Onboarding", "type": "segment", "active": true, "tags": ["new"]}]}`))
+		case "/v1/campaigns/1":
+			w.Write([]byte(`{"campaign": {"id": 1, "name": "Onboarding", "actions": [{"id": 2, "type": "email"}]}}`))
+		case "/v1/campaigns/1/actions?limit=10":
+			w.Write([]byte(`{"actions": [{"id": 2, "campaign_id": 1, "type": "email", "subject": "Welcome"}], "next": "n"}`))
+		case "/v1/campaigns/1/actions/2":
+			w.Write([]byte(`{"action": {"id": 2, "campaign_id": 1, "type": "email"}}`))
+		case "/v1/campaigns/1/metrics?period=days&steps=2&type=email",
+			"/v1/campaigns/1/actions/2/metrics":
+			w.Write([]byte(`{"metric": {"series": {"sent": [10, 20], "delivered": [9, 19], "opened": [5, 6], "clicked": [1, 2], "converted": [0, 1]}}}`))
+		case "/v1/campaigns/404":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("Unexpected request: %s %s", req.Method, req.URL.RequestURI())
+		}
+	}))
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	return api, srv
+}
+
+func TestListCampaigns(t *testing.T) {
+	api, srv := campaignsAppServer(t)
+	defer srv.Close()
+
+	resp, err := api.ListCampaigns(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.ListCampaignsResponse{
+		Campaigns: []customerio.Campaign{{ID: 1, Name: "Onboarding", Type: "segment", Active: true, Tags: []string{"new"}}},
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+}
+
+func TestGetCampaign(t *testing.T) {
+	api, srv := campaignsAppServer(t)
+	defer srv.Close()
+
+	resp, err := api.GetCampaign(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.GetCampaignResponse{
+		Campaign: customerio.Campaign{ID: 1, Name: "Onboarding", Actions: []customerio.CampaignAction{{ID: 2, Type: "email"}}},
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+
+	_, err = api.GetCampaign(context.Background(), 404)
+	if !customerio.IsNotFound(err) {
+		t.Errorf("Expected not found error, got: %#v", err)
+	}
+}
+
+func TestCampaignActions(t *testing.T) {
+	api, srv := campaignsAppServer(t)
+	defer srv.Close()
+
+	list, err := api.ListCampaignActions(context.Background(), 1, customerio.PageLimit(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectList := &customerio.ListCampaignActionsResponse{
+		Actions: []customerio.CampaignAction{{ID: 2, CampaignID: 1, Type: "email", Subject: "Welcome"}},
+		Next:    "n",
+	}
+	if !reflect.DeepEqual(list, expectList) {
+		t.Errorf("Expect: %#v, Got: %#v", expectList, list)
+	}
+
+	action, err := api.GetCampaignAction(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectAction := &customerio.GetCampaignActionResponse{
+		Action: customerio.CampaignAction{ID: 2, CampaignID: 1, Type: "email"},
+	}
+	if !reflect.DeepEqual(action, expectAction) {
+		t.Errorf("Expect: %#v, Got: %#v", expectAction, action)
+	}
+}
+
+func TestCampaignMetrics(t *testing.T) {
+	api, srv := campaignsAppServer(t)
+	defer srv.Close()
+
+	expect := &customerio.GetMetricsResponse{
+		Metric: customerio.Metrics{
+			Series: customerio.MetricSeries{
+				Sent:      []int{10, 20},
+				Delivered: []int{9, 19},
+				Opened:    []int{5, 6},
+				Clicked:   []int{1, 2},
+				Converted: []int{0, 1},
+			},
+		},
+	}
+
+	resp, err := api.GetCampaignMetrics(context.Background(), 1, &customerio.MetricsRequest{
+		Period: customerio.MetricsPeriodDays,
+		Steps:  2,
+		Type:   "email",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+
+	resp, err = api.GetCampaignActionMetrics(context.Background(), 1, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+}
@@ -7,11 +7,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const DefaultUserAgent = "Customer.io Go Client/" + Version
@@ -24,19 +24,58 @@ type CustomerIO struct {
 	UserAgent string
 	IDType    string
 	Client    *http.Client
+
+	httpConfig
+	shadow *shadow
 }
 
 // CustomerIOError is returned by any method that fails at the API level
 type CustomerIOError struct {
 	status int
+	method string
 	url    string
 	body   []byte
+	header http.Header
 }
 
 func (e *CustomerIOError) Error() string {
 	return fmt.Sprintf("%v: %v %v", e.status, e.url, string(e.body))
 }
 
+// StatusCode returns the HTTP status code of the response.
+func (e *CustomerIOError) StatusCode() int { return e.status }
+
+// Method returns the HTTP method of the request.
+func (e *CustomerIOError) Method() string { return e.method }
+
+// URL returns the URL of the request.
+func (e *CustomerIOError) URL() string { return e.url }
+
+// Body returns the raw body of the response.
+func (e *CustomerIOError) Body() []byte { return e.body }
+
+// Messages returns the error messages found in the response body, if any.
+func (e *CustomerIOError) Messages() []string { return parseErrorMessages(e.body) }
+
+// RetryAfter returns the delay requested by the Retry-After header, or 0.
+func (e *CustomerIOError) RetryAfter() time.Duration { return parseRetryAfter(e.header) }
+
+// RequestID returns the request ID header of the response, useful when contacting support.
+func (e *CustomerIOError) RequestID() string { return requestID(e.header) }
+
+// Is reports whether the error matches one of ErrRateLimited, ErrAuth, ErrNotFound or ErrTemporary.
+func (e *CustomerIOError) Is(target error) bool { return statusIs(e.status, target) }
+
+func newCustomerIOError(method, url string, resp *http.Response, body []byte) *CustomerIOError {
+	return &CustomerIOError{
+		status: resp.StatusCode,
+		method: method,
+		url:    url,
+		body:   body,
+		header: resp.Header,
+	}
+}
+
 // ParamError is an error returned if a parameter to the track API is invalid.
 type ParamError struct {
 	Param string // Param is the name of the parameter.
@@ -46,7 +85,7 @@ func (e ParamError) Error() string { return e.Param + ": missing" }
 
 // NewTrackClient prepares a client for use with the Customer.io track API, see: https://customer.io/docs/api/#apitrackintroduction
 // using a Tracking Site ID and API Key pair from https://fly.customer.io/settings/api_credentials
-func NewTrackClient(siteID, apiKey string, opts ...option) *CustomerIO {
+func NewTrackClient(siteID, apiKey string, opts ...Option) *CustomerIO {
 	client := &http.Client{
 		Transport: &http.Transport{
 			MaxIdleConnsPerHost: 100,
@@ -90,18 +129,7 @@ func (c *CustomerIO) Identify(customerID string, attributes map[string]interface
 
 // TrackCtx sends a single event to Customer.io for the supplied user
 func (c *CustomerIO) TrackCtx(ctx context.Context, customerID string, eventName string, data map[string]interface{}) error {
-	if customerID == "" {
-		return ParamError{Param: "customerID"}
-	}
-	if eventName == "" {
-		return ParamError{Param: "eventName"}
-	}
-	return c.request(ctx, "POST",
-		fmt.Sprintf("%s/api/v1/customers/%s/events", c.URL, url.PathEscape(customerID)),
-		map[string]interface{}{
-			"name": eventName,
-			"data": data,
-		})
+	return c.trackEvent(ctx, customerID, "", eventName, "eventName", data)
 }
 
 // Track sends a single event to Customer.io for the supplied user
@@ -111,15 +139,55 @@ func (c *CustomerIO) Track(customerID string, eventName string, data map[string]
 
 // TrackAnonymousCtx sends a single event to Customer.io for the anonymous user
 func (c *CustomerIO) TrackAnonymousCtx(ctx context.Context, anonymousID, eventName string, data map[string]interface{}) error {
-	if eventName == "" {
-		return ParamError{Param: "eventName"}
+	return c.trackAnonymousEvent(ctx, anonymousID, "", eventName, "eventName", data)
+}
+
+// TrackAnonymous sends a single event to Customer.io for the anonymous user
+func (c *CustomerIO) TrackAnonymous(anonymousID, eventName string, data map[string]interface{}) error {
+	return c.TrackAnonymousCtx(context.Background(), anonymousID, eventName, data)
+}
+
+// TrackPageView sends a page view of the given url to Customer.io for the supplied user
+func (c *CustomerIO) TrackPageView(ctx context.Context, customerID, url string, data map[string]interface{}) error {
+	return c.trackEvent(ctx, customerID, "page", url, "url", data)
+}
+
+// TrackScreenView sends a mobile screen view to Customer.io for the supplied user
+func (c *CustomerIO) TrackScreenView(ctx context.Context, customerID, screenName string, data map[string]interface{}) error {
+	return c.trackEvent(ctx, customerID, "screen", screenName, "screenName", data)
+}
+
+// TrackAnonymousPageView sends a page view of the given url to Customer.io for the anonymous user
+func (c *CustomerIO) TrackAnonymousPageView(ctx context.Context, anonymousID, url string, data map[string]interface{}) error {
+	return c.trackAnonymousEvent(ctx, anonymousID, "page", url, "url", data)
+}
+
+// TrackAnonymousScreenView sends a mobile screen view to Customer.io for the anonymous user
+func (c *CustomerIO) TrackAnonymousScreenView(ctx context.Context, anonymousID, screenName string, data map[string]interface{}) error {
+	return c.trackAnonymousEvent(ctx, anonymousID, "screen", screenName, "screenName", data)
+}
+
+// trackEvent sends an event of the given type, or a custom event when eventType is empty.
+// nameParam names the name argument in errors.
+func (c *CustomerIO) trackEvent(ctx context.Context, customerID, eventType, name, nameParam string, data map[string]interface{}) error {
+	if customerID == "" {
+		return ParamError{Param: "customerID"}
+	}
+	if name == "" {
+		return ParamError{Param: nameParam}
 	}
+	return c.request(ctx, "POST",
+		fmt.Sprintf("%s/api/v1/customers/%s/events", c.URL, url.PathEscape(customerID)),
+		eventPayload(eventType, name, data))
+}
 
-	payload := map[string]interface{}{
-		"name": eventName,
-		"data": data,
+// trackAnonymousEvent is trackEvent for anonymous users.
+func (c *CustomerIO) trackAnonymousEvent(ctx context.Context, anonymousID, eventType, name, nameParam string, data map[string]interface{}) error {
+	if name == "" {
+		return ParamError{Param: nameParam}
 	}
 
+	payload := eventPayload(eventType, name, data)
 	if anonymousID != "" {
 		payload["anonymous_id"] = anonymousID
 	}
@@ -127,9 +195,15 @@ func (c *CustomerIO) TrackAnonymousCtx(ctx context.Context, anonymousID, eventNa
 	return c.request(ctx, "POST", fmt.Sprintf("%s/api/v1/events", c.URL), payload)
 }
 
-// TrackAnonymous sends a single event to Customer.io for the anonymous user
-func (c *CustomerIO) TrackAnonymous(anonymousID, eventName string, data map[string]interface{}) error {
-	return c.TrackAnonymousCtx(context.Background(), anonymousID, eventName, data)
+func eventPayload(eventType, name string, data map[string]interface{}) map[string]interface{} {
+	payload := map[string]interface{}{
+		"name": name,
+		"data": data,
+	}
+	if eventType != "" {
+		payload["type"] = eventType
+	}
+	return payload
 }
 
 // DeleteCtx deletes a customer
@@ -147,52 +221,61 @@ func (c *CustomerIO) auth() string {
 }
 
 func (c *CustomerIO) request(ctx context.Context, method, url string, body interface{}) error {
-	var req *http.Request
-	if body != nil {
-		j, err := json.Marshal(body)
-		if err != nil {
-			return err
-		}
+	resp, responseBody, err := c.send(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
 
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(j))
-		if err != nil {
-			return err
-		}
-		req = req.WithContext(ctx)
+	if resp.StatusCode != http.StatusOK {
+		return newCustomerIOError(method, url, resp, responseBody)
+	}
 
-		req.Header.Add("User-Agent", c.UserAgent)
-		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("Content-Length", strconv.Itoa(len(j)))
-	} else {
+	return nil
+}
+
+// send performs the HTTP call, retrying it when a retry policy is configured,
+// and returns the final response along with its body.
+func (c *CustomerIO) send(ctx context.Context, method, url string, body interface{}) (*http.Response, []byte, error) {
+	var j []byte
+	if body != nil {
 		var err error
-		req, err = http.NewRequest(method, url, nil)
+		j, err = json.Marshal(body)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Basic %v", c.auth()))
-
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return err
+	// A dry-run client sends nothing, not even its shadow copies.
+	if c.shadow != nil && c.dryRun == nil {
+		c.shadow.send(c.URL, method, url, j)
 	}
-	defer resp.Body.Close()
 
-	responseBody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
+	return doHTTP(ctx, c.Client, c.URL, c.httpConfig, func() (*http.Request, error) {
+		return c.newRequest(ctx, method, url, j)
+	})
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return &CustomerIOError{
-			status: resp.StatusCode,
-			url:    url,
-			body:   responseBody,
+// newRequest builds an authenticated request, with body as its JSON body unless it is nil.
+func (c *CustomerIO) newRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	if body == nil {
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return nil, err
 		}
+		req.Header.Add("Authorization", fmt.Sprintf("Basic %v", c.auth()))
+		return req, nil
 	}
 
-	return nil
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", c.UserAgent)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Length", strconv.Itoa(len(body)))
+	req.Header.Add("Authorization", fmt.Sprintf("Basic %v", c.auth()))
+	return req, nil
 }
 
 type IdentifierType string
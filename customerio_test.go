@@ -2,6 +2,7 @@ package customerio_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -123,6 +124,70 @@ func TestTrackAnonymous(t *testing.T) {
 	}
 }
 
+func TestTrackPageView(t *testing.T) {
+	ctx := context.Background()
+	data := map[string]interface{}{
+		"referrer": "https://example.com",
+	}
+
+	err := cio.TrackPageView(ctx, "", "https://example.com/pricing", data)
+	checkParamError(t, err, "customerID")
+	err = cio.TrackPageView(ctx, "1", "", data)
+	checkParamError(t, err, "url")
+	err = cio.TrackAnonymousPageView(ctx, "anon123", "", data)
+	checkParamError(t, err, "url")
+
+	expect("POST", "/api/v1/customers/1/events", map[string]interface{}{
+		"type": "page",
+		"name": "https://example.com/pricing",
+		"data": data,
+	})
+	if err := cio.TrackPageView(ctx, "1", "https://example.com/pricing", data); err != nil {
+		t.Error(err.Error())
+	}
+
+	expect("POST", "/api/v1/events", map[string]interface{}{
+		"type":         "page",
+		"name":         "https://example.com/pricing",
+		"anonymous_id": "anon123",
+		"data":         data,
+	})
+	if err := cio.TrackAnonymousPageView(ctx, "anon123", "https://example.com/pricing", data); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestTrackScreenView(t *testing.T) {
+	ctx := context.Background()
+	data := map[string]interface{}{
+		"tab": "settings",
+	}
+
+	err := cio.TrackScreenView(ctx, "", "Settings", data)
+	checkParamError(t, err, "customerID")
+	err = cio.TrackScreenView(ctx, "1", "", data)
+	checkParamError(t, err, "screenName")
+
+	expect("POST", "/api/v1/customers/1/events", map[string]interface{}{
+		"type": "screen",
+		"name": "Settings",
+		"data": data,
+	})
+	if err := cio.TrackScreenView(ctx, "1", "Settings", data); err != nil {
+		t.Error(err.Error())
+	}
+
+	expect("POST", "/api/v1/events", map[string]interface{}{
+		"type":         "screen",
+		"name":         "Settings",
+		"anonymous_id": "anon123",
+		"data":         data,
+	})
+	if err := cio.TrackAnonymousScreenView(ctx, "anon123", "Settings", data); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 func TestDelete(t *testing.T) {
 	err := cio.Delete("")
 	checkParamError(t, err, "customerID")
@@ -0,0 +1,307 @@
+package customeriotest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// redacted replaces the values removed from a cassette.
+const redacted = "REDACTED"
+
+// MatchMode selects how a Replayer pairs requests with recorded exchanges.
+type MatchMode int
+
+const (
+	// MatchStrict expects the requests in the order they were recorded, with the same
+	// method, path, query and JSON body.
+	MatchStrict MatchMode = iota
+	// MatchLenient answers a request with the first unused exchange with the same method and path,
+	// ignoring the query, the body and the order of the requests.
+	MatchLenient
+)
+
+// CassetteConfig configures a Recorder or a Replayer. Use the same config for both.
+type CassetteConfig struct {
+	// RedactHeaders lists request headers whose value is not recorded, in addition to Authorization.
+	RedactHeaders []string
+	// RedactFields lists JSON fields, at any depth, whose value is not recorded in
+	// request and response bodies, e.g. "email".
+	RedactFields []string
+	// Match selects how requests are matched on replay. Defaults to MatchStrict.
+	Match MatchMode
+}
+
+// Exchange is a request and its response, as stored in a cassette.
+type Exchange struct {
+	Method         string      `json:"method"`
+	URL            string      `json:"url"` // URL holds the path and query of the request, without the host.
+	RequestHeader  http.Header `json:"request_header,omitempty"`
+	RequestBody    string      `json:"request_body,omitempty"`
+	StatusCode     int         `json:"status_code"`
+	ResponseHeader http.Header `json:"response_header,omitempty"`
+	ResponseBody   string      `json:"response_body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper recording the exchanges it forwards to a cassette file,
+// one JSON object per line. Use it as the Transport of the http.Client given to
+// customerio.WithHTTPClient.
+type Recorder struct {
+	next   http.RoundTripper
+	config CassetteConfig
+
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewRecorder creates, or truncates, the cassette at path and records the exchanges
+// sent through next, or http.DefaultTransport when next is nil. Call Close once done.
+func NewRecorder(path string, next http.RoundTripper, config CassetteConfig) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{next: next, config: config, f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	out := req.Clone(req.Context())
+	out.Body, out.ContentLength = nil, 0
+	if len(reqBody) > 0 {
+		out.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+		out.ContentLength = int64(len(reqBody))
+	}
+
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	header := req.Header.Clone()
+	for _, h := range append([]string{"Authorization"}, r.config.RedactHeaders...) {
+		if header.Get(h) != "" {
+			header.Set(h, redacted)
+		}
+	}
+
+	e := Exchange{
+		Method:         req.Method,
+		URL:            req.URL.RequestURI(),
+		RequestHeader:  header,
+		RequestBody:    redactBody(reqBody, r.config.RedactFields),
+		StatusCode:     resp.StatusCode,
+		ResponseHeader: resp.Header,
+		ResponseBody:   redactBody(respBody, r.config.RedactFields),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(e); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Close closes the cassette file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// Replayer is an http.RoundTripper answering requests from a cassette written by a
+// Recorder, without any network access.
+type Replayer struct {
+	config CassetteConfig
+
+	mu        sync.Mutex
+	exchanges []Exchange
+	used      []bool
+	next      int
+}
+
+// NewReplayer loads the cassette at path.
+func NewReplayer(path string, config CassetteConfig) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var exchanges []Exchange
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 16*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e Exchange
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("cassette %s, exchange %d: %w", path, len(exchanges)+1, err)
+		}
+		exchanges = append(exchanges, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Replayer{config: config, exchanges: exchanges, used: make([]bool, len(exchanges))}, nil
+}
+
+// RoundTrip implements http.RoundTripper. It returns an error when no recorded exchange matches req.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.match(req, body)
+	if err != nil {
+		return nil, err
+	}
+	r.used[i] = true
+	e := r.exchanges[i]
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.ResponseHeader.Clone(),
+		Body:          ioutil.NopCloser(strings.NewReader(e.ResponseBody)),
+		ContentLength: int64(len(e.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// Remaining returns the number of recorded exchanges not replayed yet.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Replayer) match(req *http.Request, body []byte) (int, error) {
+	if r.config.Match == MatchLenient {
+		for i, e := range r.exchanges {
+			if !r.used[i] && e.Method == req.Method && pathOf(e.URL) == req.URL.EscapedPath() {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("customeriotest: no recorded exchange for %s %s", req.Method, req.URL.Path)
+	}
+
+	if r.next >= len(r.exchanges) {
+		return 0, fmt.Errorf("customeriotest: unexpected request %s %s, all %d exchanges were replayed", req.Method, req.URL.RequestURI(), len(r.exchanges))
+	}
+	i := r.next
+	e := r.exchanges[i]
+	if e.Method != req.Method || e.URL != req.URL.RequestURI() {
+		return 0, fmt.Errorf("customeriotest: exchange %d: expected %s %s, got %s %s", i+1, e.Method, e.URL, req.Method, req.URL.RequestURI())
+	}
+	if got := redactBody(body, r.config.RedactFields); !sameBody(e.RequestBody, got) {
+		return 0, fmt.Errorf("customeriotest: exchange %d: request body differs, expected %s, got %s", i+1, e.RequestBody, got)
+	}
+	r.next++
+	return i, nil
+}
+
+func pathOf(uri string) string {
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		return uri[:i]
+	}
+	return uri
+}
+
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+// redactBody replaces the given fields of a JSON body. Other bodies are returned as is.
+func redactBody(body []byte, fields []string) string {
+	if len(fields) == 0 || len(body) == 0 {
+		return string(body)
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+
+	redact := map[string]bool{}
+	for _, f := range fields {
+		redact[f] = true
+	}
+	b, err := json.Marshal(redactValue(v, redact))
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+func redactValue(v interface{}, fields map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if fields[k] {
+				v[k] = redacted
+			} else {
+				v[k] = redactValue(child, fields)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child, fields)
+		}
+	}
+	return v
+}
+
+// sameBody compares two bodies as JSON values when both are JSON, byte for byte otherwise.
+func sameBody(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,120 @@
+package customeriotest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+	"github.com/customerio/go-customerio/v3/customeriotest"
+)
+
+// exercise makes the calls recorded and replayed by the cassette tests.
+func exercise(t *testing.T, region customerio.Region, transport http.RoundTripper) {
+	opt := customerio.WithHTTPClient(&http.Client{Transport: transport})
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRegion(region), opt)
+	api := customerio.NewAPIClient("app_api_key", customerio.WithRegion(region), opt)
+	ctx := context.Background()
+
+	if err := track.IdentifyCtx(ctx, "1", map[string]interface{}{"email": "one@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.AddPeopleToSegment(ctx, 3, []string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := api.SendEmail(ctx, &customerio.SendEmailRequest{
+		TransactionalMessageID: "welcome",
+		Identifiers:            map[string]string{"id": "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.DeliveryID != "delivery_1" {
+		t.Errorf("Unexpected delivery id: %s", resp.DeliveryID)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+	config := customeriotest.CassetteConfig{RedactFields: []string{"email"}}
+
+	srv := customeriotest.NewServer()
+	rec, err := customeriotest.NewRecorder(cassette, nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercise(t, srv.Region(), rec)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	b, err := ioutil.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(b), "\n"); lines != 3 {
+		t.Errorf("Expected 3 exchanges, got %d", lines)
+	}
+	if strings.Contains(string(b), "one@example.com") || strings.Contains(string(b), "Basic ") || strings.Contains(string(b), "app_api_key") {
+		t.Errorf("Expected secrets to be redacted, got: %s", b)
+	}
+
+	// the server is gone: requests can only be answered from the cassette.
+	offline := customerio.Region{ApiURL: "http://127.0.0.1:1", TrackURL: "http://127.0.0.1:1"}
+	rep, err := customeriotest.NewReplayer(cassette, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercise(t, offline, rep)
+	if n := rep.Remaining(); n != 0 {
+		t.Errorf("Expected every exchange to be replayed, %d remain", n)
+	}
+}
+
+func TestReplayMatching(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+	err := ioutil.WriteFile(cassette, []byte(
+		`{"method":"PUT","url":"/api/v1/customers/1","request_body":"{\"plan\":\"basic\"}","status_code":200,"response_body":"{}"}`+"\n"+
+			`{"method":"POST","url":"/api/v1/customers/1/events","request_body":"{\"name\":\"purchase\",\"data\":null}","status_code":200,"response_body":"{}"}`+"\n"),
+		0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offline := customerio.Region{ApiURL: "http://127.0.0.1:1", TrackURL: "http://127.0.0.1:1"}
+
+	strict, err := customeriotest.NewReplayer(cassette, customeriotest.CassetteConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRegion(offline),
+		customerio.WithHTTPClient(&http.Client{Transport: strict}))
+	if err := track.Track("1", "purchase", nil); err == nil {
+		t.Error("Expected strict matching to reject a request out of order")
+	}
+	if err := track.Identify("1", map[string]interface{}{"plan": "premium"}); err == nil {
+		t.Error("Expected strict matching to reject a different body")
+	}
+	if err := track.Identify("1", map[string]interface{}{"plan": "basic"}); err != nil {
+		t.Error(err)
+	}
+
+	lenient, err := customeriotest.NewReplayer(cassette, customeriotest.CassetteConfig{Match: customeriotest.MatchLenient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	track = customerio.NewTrackClient("site_id", "api_key", customerio.WithRegion(offline),
+		customerio.WithHTTPClient(&http.Client{Transport: lenient}))
+	if err := track.Track("1", "refund", nil); err != nil {
+		t.Error(err)
+	}
+	if err := track.Identify("1", map[string]interface{}{"plan": "premium"}); err != nil {
+		t.Error(err)
+	}
+	if err := track.Identify("1", nil); err == nil {
+		t.Error("Expected an error once the matching exchanges are used")
+	}
+}
@@ -0,0 +1,877 @@
+// Package customeriotest provides an in-process fake of the Customer.io track and
+// app APIs, for testing code built on the customerio package.
+//
+//	srv := customeriotest.NewServer()
+//	defer srv.Close()
+//
+//	track := srv.TrackClient()
+//	track.Identify("1", map[string]interface{}{"email": "user@example.com"})
+//
+//	customer, _ := srv.Customer("1")
+//
+// The server records the calls it receives in memory: customers and their devices,
+// events, merges, segment membership, objects and relationships, and transactional messages. It only models
+// what the clients of the customerio package send, and accepts any credentials.
+package customeriotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// Customer is a person recorded by the server.
+type Customer struct {
+	ID         string
+	CioID      string
+	Attributes map[string]interface{}
+	Devices    map[string]Device
+	Suppressed bool
+	// Relationships relate the customer to objects, in the order they were added.
+	Relationships []customerio.Relationship
+}
+
+// Object is an object recorded by the server.
+type Object struct {
+	Type       customerio.ObjectType
+	ID         customerio.ObjectID
+	Attributes map[string]interface{}
+}
+
+// Device is a device recorded for a customer.
+type Device struct {
+	ID         string
+	Platform   string
+	LastUsed   string
+	Attributes map[string]interface{}
+}
+
+// Event is an event recorded by the server.
+type Event struct {
+	CustomerID  string // CustomerID is empty for anonymous events.
+	AnonymousID string
+	// Type is the type of event: empty for custom events, page or screen.
+	Type      string
+	Name      string
+	Data      map[string]interface{}
+	Timestamp int64
+}
+
+// Merge is a merge of two customers recorded by the server.
+type Merge struct {
+	Primary   customerio.Identifier
+	Secondary customerio.Identifier
+}
+
+// Message is a transactional message sent through the server.
+type Message struct {
+	// Type is the type of message: email, push, sms or in_app.
+	Type string
+	// DeliveryID is the delivery id returned to the client.
+	DeliveryID string
+	// TransactionalMessageID is the template of the message, if any.
+	TransactionalMessageID string
+	// Identifiers identify the recipient.
+	Identifiers map[string]string
+	// Body is the request as sent by the client.
+	Body json.RawMessage
+}
+
+// Decode unmarshals the request of the message into v, e.g. a *customerio.SendEmailRequest.
+func (m Message) Decode(v interface{}) error {
+	return json.Unmarshal(m.Body, v)
+}
+
+// Request is an HTTP request received by the server.
+type Request struct {
+	Method string
+	// Route is the template of the request path, e.g. /api/v1/customers/{id}/events.
+	Route string
+	// Path is the actual request path.
+	Path string
+	Body []byte
+}
+
+// Fault changes how the server answers the requests to a route.
+type Fault struct {
+	// StatusCode is the status to answer with instead of handling the request. 0 handles it as usual.
+	StatusCode int
+	// Body is the body sent along with StatusCode.
+	Body string
+	// Latency delays the answer.
+	Latency time.Duration
+	// Times is the number of requests affected, 0 for all of them.
+	Times int
+}
+
+// Server is a fake Customer.io API server.
+type Server struct {
+	// URL is the base URL of the server, for both the track and app APIs.
+	URL string
+
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	customers map[string]*Customer
+	objects   map[objectKey]*Object
+	events    []Event
+	merges    []Merge
+	segments  map[int]map[string]bool
+	messages  []Message
+	requests  []Request
+	faults    map[string]*Fault
+	nextID    int
+}
+
+// NewServer starts a Server. Call Close once done with it.
+func NewServer() *Server {
+	s := &Server{}
+	s.Reset()
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Region points clients at the server, with customerio.WithRegion.
+func (s *Server) Region() customerio.Region {
+	return customerio.Region{ApiURL: s.URL, TrackURL: s.URL}
+}
+
+// TrackClient returns a track client sending to the server, configured with opts.
+func (s *Server) TrackClient(opts ...customerio.Option) *customerio.CustomerIO {
+	opts = append([]customerio.Option{customerio.WithRegion(s.Region())}, opts...)
+	return customerio.NewTrackClient("site_id", "api_key", opts...)
+}
+
+// APIClient returns an app API client sending to the server, configured with opts.
+func (s *Server) APIClient(opts ...customerio.Option) *customerio.APIClient {
+	opts = append([]customerio.Option{customerio.WithRegion(s.Region())}, opts...)
+	return customerio.NewAPIClient("app_api_key", opts...)
+}
+
+// Reset forgets all the recorded state and faults.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.customers = map[string]*Customer{}
+	s.objects = map[objectKey]*Object{}
+	s.events = nil
+	s.merges = nil
+	s.segments = map[int]map[string]bool{}
+	s.messages = nil
+	s.requests = nil
+	s.faults = map[string]*Fault{}
+	s.nextID = 0
+}
+
+// InjectFault makes the server answer the requests matching method and route with f.
+// route is a template as reported by Request.Route, e.g. /api/v1/customers/{id}/events.
+// An empty method matches any method.
+func (s *Server) InjectFault(method, route string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[method+" "+route] = &f
+}
+
+// Customer returns the customer with the given id.
+func (s *Server) Customer(id string) (Customer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.customers[id]
+	if !ok {
+		return Customer{}, false
+	}
+	return c.copy(), true
+}
+
+// Customers returns all the customers, ordered by id.
+func (s *Server) Customers() []Customer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customers := make([]Customer, 0, len(s.customers))
+	for _, c := range s.customers {
+		customers = append(customers, c.copy())
+	}
+	sort.Slice(customers, func(i, j int) bool { return customers[i].ID < customers[j].ID })
+	return customers
+}
+
+// Object returns the object with the given type and id.
+func (s *Server) Object(objectType customerio.ObjectType, objectID customerio.ObjectID) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.objects[objectKey{objectType, objectID}]
+	if !ok {
+		return Object{}, false
+	}
+	return o.copy(), true
+}
+
+// Objects returns all the objects, ordered by type and id.
+func (s *Server) Objects() []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	objects := make([]Object, 0, len(s.objects))
+	for _, o := range s.objects {
+		objects = append(objects, o.copy())
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].Type != objects[j].Type {
+			return objects[i].Type < objects[j].Type
+		}
+		return objects[i].ID < objects[j].ID
+	})
+	return objects
+}
+
+// Events returns the recorded events, in the order they were received.
+func (s *Server) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+// Merges returns the recorded merges, in the order they were received.
+func (s *Server) Merges() []Merge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Merge(nil), s.merges...)
+}
+
+// SegmentMembers returns the ids of the members of a manual segment, sorted.
+func (s *Server) SegmentMembers(segmentID int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.members(segmentID)
+}
+
+// Messages returns the transactional messages sent, in the order they were received.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Requests returns all the requests received, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (c *Customer) copy() Customer {
+	cp := *c
+	cp.Attributes = make(map[string]interface{}, len(c.Attributes))
+	for k, v := range c.Attributes {
+		cp.Attributes[k] = v
+	}
+	cp.Devices = make(map[string]Device, len(c.Devices))
+	for k, v := range c.Devices {
+		cp.Devices[k] = v
+	}
+	cp.Relationships = append([]customerio.Relationship(nil), c.Relationships...)
+	return cp
+}
+
+type objectKey struct {
+	typ customerio.ObjectType
+	id  customerio.ObjectID
+}
+
+func (o *Object) copy() Object {
+	cp := *o
+	cp.Attributes = make(map[string]interface{}, len(o.Attributes))
+	for k, v := range o.Attributes {
+		cp.Attributes[k] = v
+	}
+	return cp
+}
+
+func (s *Server) members(segmentID int) []string {
+	ids := make([]string, 0, len(s.segments[segmentID]))
+	for id := range s.segments[segmentID] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// handler handles a request matching a route. It returns the status and the value to
+// answer with, which is encoded as JSON.
+type handler func(s *Server, r *request) (int, interface{})
+
+type request struct {
+	params map[string]string
+	query  url.Values
+	body   []byte
+}
+
+type route struct {
+	method   string
+	template string
+	handle   handler
+}
+
+var routes = []route{
+	{"PUT", "/api/v1/customers/{id}", (*Server).identify},
+	{"DELETE", "/api/v1/customers/{id}", (*Server).deleteCustomer},
+	{"POST", "/api/v1/customers/{id}/events", (*Server).track},
+	{"PUT", "/api/v1/customers/{id}/devices", (*Server).addDevice},
+	{"DELETE", "/api/v1/customers/{id}/devices/{device_id}", (*Server).deleteDevice},
+	{"POST", "/api/v1/events", (*Server).trackAnonymous},
+	{"POST", "/api/v1/merge_customers", (*Server).merge},
+	{"POST", "/api/v1/segments/{segment_id}/add_customers", (*Server).addToSegment},
+	{"POST", "/api/v1/segments/{segment_id}/remove_customers", (*Server).removeFromSegment},
+	{"POST", "/api/v2/batch", (*Server).batch},
+	{"POST", "/api/v2/entity", (*Server).entity},
+	{"GET", "/v1/customers/{id}/attributes", (*Server).customerAttributes},
+	{"GET", "/v1/segments/{segment_id}/membership", (*Server).segmentMembership},
+	{"POST", "/v1/send/{type}", (*Server).send},
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rt, params := match(req.Method, req.URL.EscapedPath())
+	template := req.URL.Path
+	if rt != nil {
+		template = rt.template
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: req.Method, Route: template, Path: req.URL.Path, Body: body})
+	fault := s.fault(req.Method, template)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Latency > 0 {
+			t := time.NewTimer(fault.Latency)
+			select {
+			case <-req.Context().Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+		if fault.StatusCode != 0 {
+			w.WriteHeader(fault.StatusCode)
+			w.Write([]byte(fault.Body))
+			return
+		}
+	}
+
+	if req.Header.Get("Authorization") == "" {
+		writeJSON(w, http.StatusUnauthorized, errorBody("missing credentials"))
+		return
+	}
+	if rt == nil {
+		writeJSON(w, http.StatusNotFound, errorBody("no such route: "+req.Method+" "+req.URL.Path))
+		return
+	}
+
+	s.mu.Lock()
+	status, v := rt.handle(s, &request{params: params, query: req.URL.Query(), body: body})
+	s.mu.Unlock()
+
+	writeJSON(w, status, v)
+}
+
+// fault returns the fault to apply to a request, consuming one of its Times.
+func (s *Server) fault(method, template string) *Fault {
+	for _, key := range []string{method + " " + template, " " + template} {
+		f, ok := s.faults[key]
+		if !ok {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				delete(s.faults, key)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// match finds the route of a request, along with the values of its placeholders.
+func match(method, path string) (*route, map[string]string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+next:
+	for i := range routes {
+		rt := &routes[i]
+		if rt.method != method {
+			continue
+		}
+		parts := strings.Split(strings.Trim(rt.template, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+
+		params := map[string]string{}
+		for j, part := range parts {
+			if strings.HasPrefix(part, "{") {
+				v, err := url.PathUnescape(segments[j])
+				if err != nil || v == "" {
+					continue next
+				}
+				params[strings.Trim(part, "{}")] = v
+			} else if part != segments[j] {
+				continue next
+			}
+		}
+		return rt, params
+	}
+	return nil, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v == nil {
+		v = struct{}{}
+	}
+	json.NewEncoder(w).Encode(v)
+}
+
+func errorBody(msg string) interface{} {
+	return map[string]interface{}{
+		"meta": map[string]string{"error": msg},
+	}
+}
+
+func badRequest(err error) (int, interface{}) {
+	return http.StatusBadRequest, errorBody(err.Error())
+}
+
+// customer returns the customer with the given id, creating it if needed.
+func (s *Server) customer(id string) *Customer {
+	c, ok := s.customers[id]
+	if !ok {
+		s.nextID++
+		c = &Customer{
+			ID:         id,
+			CioID:      fmt.Sprintf("cio_%d", s.nextID),
+			Attributes: map[string]interface{}{},
+			Devices:    map[string]Device{},
+		}
+		s.customers[id] = c
+	}
+	return c
+}
+
+// lookup finds a customer by identifier. Customers identified by email for the
+// first time are created with their email as id.
+func (s *Server) lookup(typ customerio.IdentifierType, value string, create bool) *Customer {
+	switch typ {
+	case customerio.IdentifierTypeEmail:
+		for _, c := range s.customers {
+			if c.Attributes["email"] == value {
+				return c
+			}
+		}
+		if create {
+			c := s.customer(value)
+			c.Attributes["email"] = value
+			return c
+		}
+	case customerio.IdentifierTypeCioID:
+		for _, c := range s.customers {
+			if c.CioID == value {
+				return c
+			}
+		}
+	default:
+		if c, ok := s.customers[value]; ok || !create {
+			return c
+		}
+		return s.customer(value)
+	}
+	return nil
+}
+
+func (s *Server) identify(r *request) (int, interface{}) {
+	var attributes map[string]interface{}
+	if len(r.body) > 0 {
+		if err := json.Unmarshal(r.body, &attributes); err != nil {
+			return badRequest(err)
+		}
+	}
+	c := s.customer(r.params["id"])
+	for k, v := range attributes {
+		c.Attributes[k] = v
+	}
+	return http.StatusOK, nil
+}
+
+func (s *Server) deleteCustomer(r *request) (int, interface{}) {
+	delete(s.customers, r.params["id"])
+	return http.StatusOK, nil
+}
+
+type eventBody struct {
+	Type        string                 `json:"type"`
+	Name        string                 `json:"name"`
+	Data        map[string]interface{} `json:"data"`
+	Timestamp   int64                  `json:"timestamp"`
+	AnonymousID string                 `json:"anonymous_id"`
+}
+
+func (s *Server) track(r *request) (int, interface{}) {
+	var e eventBody
+	if err := json.Unmarshal(r.body, &e); err != nil {
+		return badRequest(err)
+	}
+	s.events = append(s.events, Event{CustomerID: r.params["id"], Type: e.Type, Name: e.Name, Data: e.Data, Timestamp: e.Timestamp})
+	return http.StatusOK, nil
+}
+
+func (s *Server) trackAnonymous(r *request) (int, interface{}) {
+	var e eventBody
+	if err := json.Unmarshal(r.body, &e); err != nil {
+		return badRequest(err)
+	}
+	s.events = append(s.events, Event{AnonymousID: e.AnonymousID, Type: e.Type, Name: e.Name, Data: e.Data, Timestamp: e.Timestamp})
+	return http.StatusOK, nil
+}
+
+func (s *Server) addDevice(r *request) (int, interface{}) {
+	var body struct {
+		Device struct {
+			ID         string                 `json:"id"`
+			Platform   string                 `json:"platform"`
+			LastUsed   string                 `json:"last_used"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"device"`
+	}
+	if err := json.Unmarshal(r.body, &body); err != nil {
+		return badRequest(err)
+	}
+	d := body.Device
+	s.customer(r.params["id"]).Devices[d.ID] = Device{ID: d.ID, Platform: d.Platform, LastUsed: d.LastUsed, Attributes: d.Attributes}
+	return http.StatusOK, nil
+}
+
+func (s *Server) deleteDevice(r *request) (int, interface{}) {
+	if c, ok := s.customers[r.params["id"]]; ok {
+		delete(c.Devices, r.params["device_id"])
+	}
+	return http.StatusOK, nil
+}
+
+// identifier reads an identifier sent as a single entry map, e.g. {"email": "user@example.com"}.
+func identifier(kv map[string]string) customerio.Identifier {
+	for k, v := range kv {
+		return customerio.Identifier{Type: customerio.IdentifierType(k), Value: v}
+	}
+	return customerio.Identifier{}
+}
+
+func (s *Server) merge(r *request) (int, interface{}) {
+	var body struct {
+		Primary   map[string]string `json:"primary"`
+		Secondary map[string]string `json:"secondary"`
+	}
+	if err := json.Unmarshal(r.body, &body); err != nil {
+		return badRequest(err)
+	}
+	s.applyMerge(identifier(body.Primary), identifier(body.Secondary))
+	return http.StatusOK, nil
+}
+
+// applyMerge records a merge and folds the secondary customer into the primary one.
+func (s *Server) applyMerge(primary, secondary customerio.Identifier) {
+	s.merges = append(s.merges, Merge{Primary: primary, Secondary: secondary})
+
+	p := s.lookup(primary.Type, primary.Value, true)
+	sec := s.lookup(secondary.Type, secondary.Value, false)
+	if p == nil || sec == nil || p == sec {
+		return
+	}
+	for k, v := range sec.Attributes {
+		if _, ok := p.Attributes[k]; !ok {
+			p.Attributes[k] = v
+		}
+	}
+	for k, d := range sec.Devices {
+		p.Devices[k] = d
+	}
+	for _, rel := range sec.Relationships {
+		p.removeRelationship(rel.ObjectType, rel.ObjectID)
+		p.Relationships = append(p.Relationships, rel)
+	}
+	for _, members := range s.segments {
+		if members[sec.ID] {
+			delete(members, sec.ID)
+			members[p.ID] = true
+		}
+	}
+	delete(s.customers, sec.ID)
+}
+
+func (s *Server) segmentIDs(r *request) (int, []string, error) {
+	var segmentID int
+	if _, err := fmt.Sscan(r.params["segment_id"], &segmentID); err != nil {
+		return 0, nil, err
+	}
+	var body struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.Unmarshal(r.body, &body); err != nil {
+		return 0, nil, err
+	}
+	return segmentID, body.IDs, nil
+}
+
+func (s *Server) addToSegment(r *request) (int, interface{}) {
+	segmentID, ids, err := s.segmentIDs(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	if s.segments[segmentID] == nil {
+		s.segments[segmentID] = map[string]bool{}
+	}
+	for _, id := range ids {
+		s.segments[segmentID][id] = true
+	}
+	return http.StatusOK, nil
+}
+
+func (s *Server) removeFromSegment(r *request) (int, interface{}) {
+	segmentID, ids, err := s.segmentIDs(r)
+	if err != nil {
+		return badRequest(err)
+	}
+	for _, id := range ids {
+		delete(s.segments[segmentID], id)
+	}
+	return http.StatusOK, nil
+}
+
+func (s *Server) batch(r *request) (int, interface{}) {
+	var body struct {
+		Batch []customerio.BatchItem `json:"batch"`
+	}
+	if err := json.Unmarshal(r.body, &body); err != nil {
+		return badRequest(err)
+	}
+
+	var errs []customerio.BatchItemError
+	for i, item := range body.Batch {
+		if err := s.applyBatchItem(item); err != "" {
+			errs = append(errs, customerio.BatchItemError{Index: i, Reason: "invalid", Field: "action", Message: err})
+		}
+	}
+	if len(errs) > 0 {
+		return http.StatusMultiStatus, map[string]interface{}{"errors": errs}
+	}
+	return http.StatusOK, nil
+}
+
+func (s *Server) entity(r *request) (int, interface{}) {
+	var item customerio.BatchItem
+	if err := json.Unmarshal(r.body, &item); err != nil {
+		return badRequest(err)
+	}
+	if err := s.applyBatchItem(item); err != "" {
+		return http.StatusBadRequest, errorBody(err)
+	}
+	return http.StatusOK, nil
+}
+
+// applyBatchItem applies a single batch operation, returning why it is not supported, if it is not.
+func (s *Server) applyBatchItem(item customerio.BatchItem) string {
+	if item.Type == "object" {
+		return s.applyObjectItem(item)
+	}
+	if item.Type != "person" {
+		return "unsupported type " + item.Type
+	}
+	if item.Action == "merge" {
+		s.applyMerge(identifier(item.Primary), identifier(item.Secondary))
+		return ""
+	}
+
+	id := identifier(item.Identifiers)
+	c := s.lookup(id.Type, id.Value, item.Action != "delete")
+	if c == nil {
+		return ""
+	}
+
+	switch item.Action {
+	case "identify":
+		for k, v := range item.Attributes {
+			c.Attributes[k] = v
+		}
+	case "event":
+		s.events = append(s.events, Event{CustomerID: c.ID, Name: item.Name, Data: item.Attributes, Timestamp: item.Timestamp})
+	case "delete":
+		delete(s.customers, c.ID)
+	case "suppress":
+		c.Suppressed = true
+	case "unsuppress":
+		c.Suppressed = false
+	case "add_device":
+		if d := item.Device; d != nil {
+			c.Devices[d.ID] = Device{ID: d.ID, Platform: d.Platform, LastUsed: d.LastUsed, Attributes: d.Attributes}
+		}
+	case "delete_device":
+		if d := item.Device; d != nil {
+			delete(c.Devices, d.ID)
+		}
+	case "add_relationships":
+		for _, rel := range item.Relationships {
+			s.object(rel.ObjectType, rel.ObjectID)
+			c.removeRelationship(rel.ObjectType, rel.ObjectID)
+			c.Relationships = append(c.Relationships, rel)
+		}
+	case "delete_relationships":
+		for _, rel := range item.Relationships {
+			c.removeRelationship(rel.ObjectType, rel.ObjectID)
+		}
+	default:
+		return "unsupported action " + item.Action
+	}
+	return ""
+}
+
+// applyObjectItem applies an operation on an object.
+func (s *Server) applyObjectItem(item customerio.BatchItem) string {
+	objectType := customerio.ObjectType(item.Identifiers["object_type_id"])
+	objectID := customerio.ObjectID(item.Identifiers["object_id"])
+	if objectType == "" || objectID == "" {
+		return "missing object_type_id or object_id"
+	}
+
+	switch item.Action {
+	case "identify":
+		o := s.object(objectType, objectID)
+		for k, v := range item.Attributes {
+			o.Attributes[k] = v
+		}
+	case "delete":
+		delete(s.objects, objectKey{objectType, objectID})
+		for _, c := range s.customers {
+			c.removeRelationship(objectType, objectID)
+		}
+	default:
+		return "unsupported action " + item.Action
+	}
+	return ""
+}
+
+// object returns the object with the given type and id, creating it if needed.
+func (s *Server) object(objectType customerio.ObjectType, objectID customerio.ObjectID) *Object {
+	key := objectKey{objectType, objectID}
+	o, ok := s.objects[key]
+	if !ok {
+		o = &Object{Type: objectType, ID: objectID, Attributes: map[string]interface{}{}}
+		s.objects[key] = o
+	}
+	return o
+}
+
+func (c *Customer) removeRelationship(objectType customerio.ObjectType, objectID customerio.ObjectID) {
+	rels := c.Relationships[:0]
+	for _, rel := range c.Relationships {
+		if rel.ObjectType != objectType || rel.ObjectID != objectID {
+			rels = append(rels, rel)
+		}
+	}
+	c.Relationships = rels
+}
+
+func (s *Server) customerAttributes(r *request) (int, interface{}) {
+	typ := customerio.IdentifierType(r.query.Get("id_type"))
+	c := s.lookup(typ, r.params["id"], false)
+	if c == nil {
+		return http.StatusNotFound, errorBody("customer not found")
+	}
+
+	email, _ := c.Attributes["email"].(string)
+	resp := customerio.CustomerAttributes{
+		ID:          c.ID,
+		Identifiers: customerio.CustomerIdentifier{ID: c.ID, Email: email, CioID: c.CioID},
+		Attributes:  c.Attributes,
+	}
+	for _, d := range c.Devices {
+		resp.Devices = append(resp.Devices, customerio.CustomerDevice{ID: d.ID, Platform: d.Platform})
+	}
+	sort.Slice(resp.Devices, func(i, j int) bool { return resp.Devices[i].ID < resp.Devices[j].ID })
+
+	return http.StatusOK, customerio.GetCustomerAttributesResponse{Customer: resp}
+}
+
+func (s *Server) segmentMembership(r *request) (int, interface{}) {
+	var segmentID int
+	if _, err := fmt.Sscan(r.params["segment_id"], &segmentID); err != nil {
+		return badRequest(err)
+	}
+
+	resp := customerio.ListCustomersInSegmentResponse{IDs: s.members(segmentID)}
+	for _, id := range resp.IDs {
+		ident := customerio.CustomerIdentifier{ID: id}
+		if c, ok := s.customers[id]; ok {
+			ident.Email, _ = c.Attributes["email"].(string)
+			ident.CioID = c.CioID
+		}
+		resp.Identifiers = append(resp.Identifiers, ident)
+	}
+	return http.StatusOK, resp
+}
+
+func (s *Server) send(r *request) (int, interface{}) {
+	var body struct {
+		TransactionalMessageID json.RawMessage   `json:"transactional_message_id"`
+		Identifiers            map[string]string `json:"identifiers"`
+	}
+	if err := json.Unmarshal(r.body, &body); err != nil {
+		return badRequest(err)
+	}
+	if len(body.Identifiers) == 0 {
+		return http.StatusBadRequest, errorBody("missing identifiers")
+	}
+
+	var templateID string
+	if err := json.Unmarshal(body.TransactionalMessageID, &templateID); err != nil {
+		templateID = string(body.TransactionalMessageID)
+	}
+
+	m := Message{
+		Type:                   r.params["type"],
+		DeliveryID:             fmt.Sprintf("delivery_%d", len(s.messages)+1),
+		TransactionalMessageID: templateID,
+		Identifiers:            body.Identifiers,
+		Body:                   json.RawMessage(r.body),
+	}
+	s.messages = append(s.messages, m)
+
+	return http.StatusOK, map[string]interface{}{
+		"delivery_id": m.DeliveryID,
+		"queued_at":   time.Now().Unix(),
+	}
+}
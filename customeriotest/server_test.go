@@ -0,0 +1,250 @@
+package customeriotest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+	"github.com/customerio/go-customerio/v3/customeriotest"
+)
+
+func TestTrack(t *testing.T) {
+	srv := customeriotest.NewServer()
+	defer srv.Close()
+
+	track := srv.TrackClient()
+	ctx := context.Background()
+
+	if err := track.Identify("1", map[string]interface{}{"email": "one@example.com", "plan": "basic"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.Identify("1", map[string]interface{}{"plan": "premium"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.Track("1", "purchase", map[string]interface{}{"price": 10.0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.TrackAnonymous("anon", "visit", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.TrackPageView(ctx, "1", "https://example.com/pricing", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.AddDevice("1", "token", "ios", map[string]interface{}{"last_used": 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.AddPeopleToSegment(ctx, 7, []string{"1", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.RemovePeopleFromSegment(ctx, 7, []string{"2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	customer, ok := srv.Customer("1")
+	if !ok {
+		t.Fatal("Expected customer 1 to exist")
+	}
+	if expect := map[string]interface{}{"email": "one@example.com", "plan": "premium"}; !reflect.DeepEqual(customer.Attributes, expect) {
+		t.Errorf("Expect: %v, Got: %v", expect, customer.Attributes)
+	}
+	if d := customer.Devices["token"]; d.Platform != "ios" || d.LastUsed != "100" {
+		t.Errorf("Unexpected device: %+v", d)
+	}
+
+	expectEvents := []customeriotest.Event{
+		{CustomerID: "1", Name: "purchase", Data: map[string]interface{}{"price": 10.0}},
+		{AnonymousID: "anon", Name: "visit"},
+		{CustomerID: "1", Type: "page", Name: "https://example.com/pricing"},
+	}
+	if events := srv.Events(); !reflect.DeepEqual(events, expectEvents) {
+		t.Errorf("Expect: %+v, Got: %+v", expectEvents, events)
+	}
+	if members := srv.SegmentMembers(7); !reflect.DeepEqual(members, []string{"1"}) {
+		t.Errorf("Unexpected segment members: %v", members)
+	}
+
+	if err := track.DeleteDevice("1", "token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := srv.Customer("1"); ok {
+		t.Error("Expected customer 1 to be deleted")
+	}
+}
+
+func TestMergeAndBatch(t *testing.T) {
+	srv := customeriotest.NewServer()
+	defer srv.Close()
+
+	track := srv.TrackClient()
+	_, err := track.Batch(context.Background(), []customerio.BatchItem{
+		customerio.BatchIdentify(customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}, map[string]interface{}{"name": "one"}),
+		customerio.BatchIdentify(customerio.Identifier{Type: customerio.IdentifierTypeEmail, Value: "two@example.com"}, map[string]interface{}{"name": "two", "plan": "basic"}),
+		customerio.BatchEvent(customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}, "signup", nil),
+		customerio.BatchAddDevice(customerio.Identifier{Type: customerio.IdentifierTypeEmail, Value: "two@example.com"}, "token", "android", nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	primary := customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}
+	secondary := customerio.Identifier{Type: customerio.IdentifierTypeEmail, Value: "two@example.com"}
+	if err := track.MergeCustomers(primary, secondary); err != nil {
+		t.Fatal(err)
+	}
+
+	if merges := srv.Merges(); !reflect.DeepEqual(merges, []customeriotest.Merge{{Primary: primary, Secondary: secondary}}) {
+		t.Errorf("Unexpected merges: %+v", merges)
+	}
+	customers := srv.Customers()
+	if len(customers) != 1 {
+		t.Fatalf("Expected a single customer after the merge, got: %+v", customers)
+	}
+	c := customers[0]
+	if c.ID != "1" || c.Attributes["name"] != "one" || c.Attributes["plan"] != "basic" || c.Devices["token"].Platform != "android" {
+		t.Errorf("Unexpected merged customer: %+v", c)
+	}
+	if events := srv.Events(); len(events) != 1 || events[0].Name != "signup" {
+		t.Errorf("Unexpected events: %+v", events)
+	}
+}
+
+func TestObjects(t *testing.T) {
+	srv := customeriotest.NewServer()
+	defer srv.Close()
+
+	track := srv.TrackClient()
+	ctx := context.Background()
+	one := customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}
+	two := customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "2"}
+	acme := customerio.Relationship{ObjectType: "1", ObjectID: "acme", Attributes: map[string]interface{}{"role": "admin"}}
+	globex := customerio.Relationship{ObjectType: "1", ObjectID: "globex"}
+
+	if err := track.IdentifyObject(ctx, "1", "acme", map[string]interface{}{"plan": "enterprise"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.AddRelationships(ctx, one, acme, globex); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.AddRelationships(ctx, two, globex); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.DeleteRelationships(ctx, one, globex); err != nil {
+		t.Fatal(err)
+	}
+
+	o, ok := srv.Object("1", "acme")
+	if !ok || o.Attributes["plan"] != "enterprise" {
+		t.Errorf("Unexpected object: %+v", o)
+	}
+	if objects := srv.Objects(); len(objects) != 2 || objects[1].ID != "globex" {
+		t.Errorf("Unexpected objects: %+v", objects)
+	}
+	c, _ := srv.Customer("1")
+	if expect := []customerio.Relationship{acme}; !reflect.DeepEqual(c.Relationships, expect) {
+		t.Errorf("Expect: %+v, Got: %+v", expect, c.Relationships)
+	}
+
+	if err := track.DeleteObject(ctx, "1", "globex"); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := srv.Customer("2"); len(c.Relationships) != 0 {
+		t.Errorf("Expected the relationships of a deleted object to be removed, got: %+v", c.Relationships)
+	}
+	if _, ok := srv.Object("1", "globex"); ok {
+		t.Error("Expected object globex to be deleted")
+	}
+}
+
+func TestAPI(t *testing.T) {
+	srv := customeriotest.NewServer()
+	defer srv.Close()
+
+	track := srv.TrackClient()
+	api := srv.APIClient()
+	ctx := context.Background()
+
+	track.Identify("1", map[string]interface{}{"email": "one@example.com"})
+	track.AddPeopleToSegment(ctx, 3, []string{"1"})
+
+	attrs, err := api.GetCustomerAttributes(ctx, "one@example.com", customerio.IDTypeEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.Customer.ID != "1" || attrs.Customer.Identifiers.Email != "one@example.com" {
+		t.Errorf("Unexpected customer: %+v", attrs.Customer)
+	}
+	if _, err := api.GetCustomerAttributes(ctx, "2", customerio.IDTypeID); !customerio.IsNotFound(err) {
+		t.Errorf("Expected a not found error, got: %v", err)
+	}
+
+	var members []string
+	it := api.SegmentMembers(ctx, 3)
+	for it.Next() {
+		members = append(members, it.Identifier().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(members, []string{"1"}) {
+		t.Errorf("Unexpected segment members: %v", members)
+	}
+
+	resp, err := api.SendEmail(ctx, &customerio.SendEmailRequest{
+		TransactionalMessageID: "welcome",
+		Identifiers:            map[string]string{"id": "1"},
+		MessageData:            map[string]interface{}{"name": "one"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := srv.Messages()
+	if len(messages) != 1 || messages[0].Type != "email" || messages[0].TransactionalMessageID != "welcome" || messages[0].DeliveryID != resp.DeliveryID {
+		t.Fatalf("Unexpected messages: %+v", messages)
+	}
+	var sent customerio.SendEmailRequest
+	if err := messages[0].Decode(&sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.MessageData["name"] != "one" {
+		t.Errorf("Unexpected request: %+v", sent)
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	srv := customeriotest.NewServer()
+	defer srv.Close()
+
+	srv.InjectFault("POST", "/api/v1/customers/{id}/events", customeriotest.Fault{StatusCode: http.StatusServiceUnavailable, Times: 1})
+	srv.InjectFault("", "/api/v1/customers/{id}", customeriotest.Fault{Latency: 50 * time.Millisecond})
+
+	track := srv.TrackClient(customerio.WithRetry(customerio.RetryPolicy{MaxAttempts: 2}))
+
+	if err := track.Track("1", "purchase", nil); err != nil {
+		t.Fatal(err)
+	}
+	var routes []string
+	for _, r := range srv.Requests() {
+		routes = append(routes, r.Method+" "+r.Route)
+	}
+	expect := []string{"POST /api/v1/customers/{id}/events", "POST /api/v1/customers/{id}/events"}
+	if !reflect.DeepEqual(routes, expect) {
+		t.Errorf("Expect: %v, Got: %v", expect, routes)
+	}
+	if len(srv.Events()) != 1 {
+		t.Errorf("Expected the retried event to be recorded once, got: %+v", srv.Events())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := track.IdentifyCtx(ctx, "1", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the injected latency to exceed the deadline, got: %v", err)
+	}
+}
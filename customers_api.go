@@ -0,0 +1,193 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CustomerAttributes represents a person's profile as returned by the API.
+type CustomerAttributes struct {
+	ID           string                 `json:"id"`           // ID of the customer, if any.
+	Identifiers  CustomerIdentifier     `json:"identifiers"`  // All the identifiers of the customer.
+	Attributes   map[string]interface{} `json:"attributes"`   // Attributes set on the customer.
+	Timestamps   map[string]int64       `json:"timestamps"`   // When each attribute was last updated.
+	Unsubscribed bool                   `json:"unsubscribed"` // Whether the customer is unsubscribed.
+	Devices      []CustomerDevice       `json:"devices"`      // Devices registered for the customer.
+}
+
+// CustomerDevice represents a device registered for a customer.
+type CustomerDevice struct {
+	ID       string `json:"id"`        // Device token.
+	Platform string `json:"platform"`  // ios or android.
+	LastUsed int64  `json:"last_used"` // Unix timestamp of the last time the device was used.
+}
+
+// CustomerMessage represents a message sent to a customer.
+type CustomerMessage struct {
+	ID                  string             `json:"id"`                   // Unique identifier of the message.
+	DeduplicateID       string             `json:"deduplicate_id"`       // A string in the format id:timestamp.
+	MessageTemplateID   int                `json:"msg_template_id"`      // Template the message was built from.
+	CustomerID          string             `json:"customer_id"`          // ID of the recipient.
+	CustomerIdentifiers CustomerIdentifier `json:"customer_identifiers"` // Identifiers of the recipient.
+	Recipient           string             `json:"recipient"`            // Email address, phone number or device of the recipient.
+	Subject             string             `json:"subject"`              // Subject of the message, for emails.
+	Type                string             `json:"type"`                 // Type of message: email, push, sms...
+	Created             int64              `json:"created"`              // Unix timestamp of the message creation.
+	Metrics             map[string]int64   `json:"metrics"`              // Unix timestamps of each metric, e.g. sent or opened.
+	FailureMessage      string             `json:"failure_message"`      // Reason the message failed, if it did.
+	CampaignID          int                `json:"campaign_id"`          // Campaign that sent the message, if any.
+	ActionID            int                `json:"action_id"`            // Campaign action that sent the message, if any.
+	NewsletterID        int                `json:"newsletter_id"`        // Newsletter that sent the message, if any.
+	ContentID           int                `json:"content_id"`           // Newsletter content, if any.
+	BroadcastID         int                `json:"broadcast_id"`         // Broadcast that sent the message, if any.
+}
+
+// CustomerActivity represents something that happened to a customer, such as an event or an attribute change.
+type CustomerActivity struct {
+	ID                  string                 `json:"id"`                   // Unique identifier of the activity.
+	CustomerID          string                 `json:"customer_id"`          // ID of the customer.
+	CustomerIdentifiers CustomerIdentifier     `json:"customer_identifiers"` // Identifiers of the customer.
+	Type                string                 `json:"type"`                 // Type of activity, e.g. event or attribute_change.
+	Name                string                 `json:"name"`                 // Name of the event, for events.
+	Data                map[string]interface{} `json:"data"`                 // Data attached to the activity.
+	DeliveryID          string                 `json:"delivery_id"`          // Message the activity relates to, if any.
+	DeliveryType        string                 `json:"delivery_type"`        // Type of the related message, if any.
+	Timestamp           int64                  `json:"timestamp"`            // Unix timestamp of the activity.
+}
+
+// ActivityType only returns activities of the given type, e.g. "event" or "attribute_change".
+func ActivityType(typ string) ListOption {
+	return func(q url.Values) {
+		q.Set("type", typ)
+	}
+}
+
+// ActivityName only returns events with the given name. It requires ActivityType("event").
+func ActivityName(name string) ListOption {
+	return func(q url.Values) {
+		q.Set("name", name)
+	}
+}
+
+// customerPath returns the path of a customer resource, selecting the id type when set.
+func customerPath(customerID string, idType IDType, resource string) (string, url.Values) {
+	q := url.Values{}
+	if idType != "" {
+		q.Set("id_type", string(idType))
+	}
+	return fmt.Sprintf("/v1/customers/%s/%s", url.PathEscape(customerID), resource), q
+}
+
+// getJSON performs a GET request and decodes a 200 response into v.
+func (c *APIClient) getJSON(ctx context.Context, requestPath string, v interface{}) error {
+	resp, respBody, err := c.doRequest(ctx, "GET", requestPath, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError("GET", requestPath, resp, respBody)
+	}
+	return json.Unmarshal(respBody, v)
+}
+
+// GetCustomersByEmailResponse represents the response for looking customers up by email.
+type GetCustomersByEmailResponse struct {
+	Results []CustomerIdentifier `json:"results"` // Customers having the email address.
+}
+
+// GetCustomersByEmail returns the customers having the given email address.
+func (c *APIClient) GetCustomersByEmail(ctx context.Context, email string) (*GetCustomersByEmailResponse, error) {
+	if email == "" {
+		return nil, ParamError{Param: "email"}
+	}
+
+	var response GetCustomersByEmailResponse
+	if err := c.getJSON(ctx, withQuery("/v1/customers", url.Values{"email": {email}}), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetCustomerAttributesResponse represents the response for retrieving a customer's attributes.
+type GetCustomerAttributesResponse struct {
+	Customer CustomerAttributes `json:"customer"` // The requested customer.
+}
+
+// GetCustomerAttributes returns the attributes and devices of a customer.
+// idType tells how customerID should be interpreted and defaults to IDTypeID when empty.
+func (c *APIClient) GetCustomerAttributes(ctx context.Context, customerID string, idType IDType) (*GetCustomerAttributesResponse, error) {
+	if customerID == "" {
+		return nil, ParamError{Param: "customerID"}
+	}
+
+	var response GetCustomerAttributesResponse
+	if err := c.getJSON(ctx, withQuery(customerPath(customerID, idType, "attributes")), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetCustomerSegmentsResponse represents the response for retrieving a customer's segments.
+type GetCustomerSegmentsResponse struct {
+	Segments []Segment `json:"segments"` // Segments the customer belongs to.
+}
+
+// GetCustomerSegments returns the segments a customer belongs to.
+// idType tells how customerID should be interpreted and defaults to IDTypeID when empty.
+func (c *APIClient) GetCustomerSegments(ctx context.Context, customerID string, idType IDType) (*GetCustomerSegmentsResponse, error) {
+	if customerID == "" {
+		return nil, ParamError{Param: "customerID"}
+	}
+
+	var response GetCustomerSegmentsResponse
+	if err := c.getJSON(ctx, withQuery(customerPath(customerID, idType, "segments")), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetCustomerMessagesResponse represents a page of messages sent to a customer.
+type GetCustomerMessagesResponse struct {
+	Messages []CustomerMessage `json:"messages"` // Messages sent to the customer.
+	Next     string            `json:"next"`     // Optional pagination cursor.
+}
+
+// GetCustomerMessages returns a page of the messages sent to a customer. Use PageStart and PageLimit to paginate.
+// idType tells how customerID should be interpreted and defaults to IDTypeID when empty.
+func (c *APIClient) GetCustomerMessages(ctx context.Context, customerID string, idType IDType, opts ...ListOption) (*GetCustomerMessagesResponse, error) {
+	if customerID == "" {
+		return nil, ParamError{Param: "customerID"}
+	}
+
+	path, q := customerPath(customerID, idType, "messages")
+	var response GetCustomerMessagesResponse
+	if err := c.getJSON(ctx, withQuery(path, listQuery(q, opts)), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetCustomerActivitiesResponse represents a page of a customer's activities.
+type GetCustomerActivitiesResponse struct {
+	Activities []CustomerActivity `json:"activities"` // Activities of the customer.
+	Next       string             `json:"next"`       // Optional pagination cursor.
+}
+
+// GetCustomerActivities returns a page of a customer's activities. Use ActivityType and ActivityName
+// to filter them, PageStart and PageLimit to paginate.
+// idType tells how customerID should be interpreted and defaults to IDTypeID when empty.
+func (c *APIClient) GetCustomerActivities(ctx context.Context, customerID string, idType IDType, opts ...ListOption) (*GetCustomerActivitiesResponse, error) {
+	if customerID == "" {
+		return nil, ParamError{Param: "customerID"}
+	}
+
+	path, q := customerPath(customerID, idType, "activities")
+	var response GetCustomerActivitiesResponse
+	if err := c.getJSON(ctx, withQuery(path, listQuery(q, opts)), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
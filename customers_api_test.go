@@ -0,0 +1,179 @@
+package customerio_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func customersAppServer(t *testing.T) (*customerio.APIClient, *httptest.Server) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer myKey" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch req.URL.RequestURI() {
+		case "/v1/customers?email=test%40example.com":
+			w.Write([]byte(`{"results": [{"email": "test@example.com", "id": "1", "cio_id": "a3000001"}]}`))
+		case "/v1/customers/test@example.com/attributes?id_type=email":
+			w.Write([]byte(`{
+				"customer": {
+					"id": "1",
+					"identifiers": {"email": "test@example.com", "id": "1", "cio_id": "a3000001"},
+					"attributes": {"plan": "basic"},
+					"timestamps": {"plan": 1600000000},
+					"unsubscribed": true,
+					"devices": [{"id": "d1", "platform": "ios", "last_used": 1600000001}]
+				}
+			}`))
+		case "/v1/customers/1/segments":
+			w.Write([]byte(`{"segments": [{"id": 1, "name": "VIP", "type": "manual"}]}`))
+		case "/v1/customers/1/messages?limit=1&start=abc":
+			w.Write([]byte(`{
+				"messages": [{"id": "m1", "type": "email", "subject": "hi", "metrics": {"sent": 1600000002}, "campaign_id": 3}],
+				"next": "def"
+			}`))
+		case "/v1/customers/1/activities?name=purchase&type=event":
+			w.Write([]byte(`{
+				"activities": [{"id": "a1", "type": "event", "name": "purchase", "data": {"price": "10"}, "timestamp": 1600000003}],
+				"next": ""
+			}`))
+		case "/v1/customers/2/attributes", "/v1/customers?email=missing%40example.com":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("Unexpected request: %s %s", req.Method, req.URL.RequestURI())
+		}
+	}))
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	return api, srv
+}
+
+func TestGetCustomersByEmail(t *testing.T) {
+	api, srv := customersAppServer(t)
+	defer srv.Close()
+
+	_, err := api.GetCustomersByEmail(context.Background(), "")
+	checkParamError(t, err, "email")
+
+	resp, err := api.GetCustomersByEmail(context.Background(), "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.GetCustomersByEmailResponse{
+		Results: []customerio.CustomerIdentifier{{Email: "test@example.com", ID: "1", CioID: "a3000001"}},
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+
+	_, err = api.GetCustomersByEmail(context.Background(), "missing@example.com")
+	var apiErr *customerio.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got: %v", err)
+	}
+	if apiErr.Endpoint != "/v1/customers" || strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected the error to leave the email out, got: %v", err)
+	}
+}
+
+func TestGetCustomerAttributes(t *testing.T) {
+	api, srv := customersAppServer(t)
+	defer srv.Close()
+
+	_, err := api.GetCustomerAttributes(context.Background(), "", customerio.IDTypeID)
+	checkParamError(t, err, "customerID")
+
+	resp, err := api.GetCustomerAttributes(context.Background(), "test@example.com", customerio.IDTypeEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.GetCustomerAttributesResponse{
+		Customer: customerio.CustomerAttributes{
+			ID:           "1",
+			Identifiers:  customerio.CustomerIdentifier{Email: "test@example.com", ID: "1", CioID: "a3000001"},
+			Attributes:   map[string]interface{}{"plan": "basic"},
+			Timestamps:   map[string]int64{"plan": 1600000000},
+			Unsubscribed: true,
+			Devices:      []customerio.CustomerDevice{{ID: "d1", Platform: "ios", LastUsed: 1600000001}},
+		},
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+
+	_, err = api.GetCustomerAttributes(context.Background(), "2", "")
+	if !customerio.IsNotFound(err) {
+		t.Errorf("Expected not found error, got: %#v", err)
+	}
+}
+
+func TestGetCustomerSegments(t *testing.T) {
+	api, srv := customersAppServer(t)
+	defer srv.Close()
+
+	resp, err := api.GetCustomerSegments(context.Background(), "1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.GetCustomerSegmentsResponse{
+		Segments: []customerio.Segment{{ID: 1, Name: "VIP", Type: customerio.SegmentTypeManual}},
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+}
+
+func TestGetCustomerMessages(t *testing.T) {
+	api, srv := customersAppServer(t)
+	defer srv.Close()
+
+	resp, err := api.GetCustomerMessages(context.Background(), "1", "", customerio.PageStart("abc"), customerio.PageLimit(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.GetCustomerMessagesResponse{
+		Messages: []customerio.CustomerMessage{{
+			ID:         "m1",
+			Type:       "email",
+			Subject:    "hi",
+			Metrics:    map[string]int64{"sent": 1600000002},
+			CampaignID: 3,
+		}},
+		Next: "def",
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+}
+
+func TestGetCustomerActivities(t *testing.T) {
+	api, srv := customersAppServer(t)
+	defer srv.Close()
+
+	resp, err := api.GetCustomerActivities(context.Background(), "1", "", customerio.ActivityType("event"), customerio.ActivityName("purchase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.GetCustomerActivitiesResponse{
+		Activities: []customerio.CustomerActivity{{
+			ID:        "a1",
+			Type:      "event",
+			Name:      "purchase",
+			Data:      map[string]interface{}{"price": "10"},
+			Timestamp: 1600000003,
+		}},
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+}
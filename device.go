@@ -13,11 +13,12 @@ type deviceV1 struct {
 	Attributes map[string]interface{} `json:"attributes"`
 }
 
-type deviceV2 struct {
+// Device is a device in the format of the v2 and transactional APIs. Use NewDevice to build one.
+type Device struct {
 	ID         string                 `json:"token"`
 	Platform   string                 `json:"platform"`
 	LastUsed   string                 `json:"last_used,omitempty"`
-	Attributes map[string]interface{} `json:"attributes"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
 }
 
 func newDeviceV1(deviceID, platform string, data map[string]interface{}) (*deviceV1, error) {
@@ -47,12 +48,12 @@ func newDeviceV1(deviceID, platform string, data map[string]interface{}) (*devic
 	return d, nil
 }
 
-func NewDevice(deviceID, platform string, data map[string]interface{}) (*deviceV2, error) {
+func NewDevice(deviceID, platform string, data map[string]interface{}) (*Device, error) {
 	d, err := newDeviceV1(deviceID, platform, data)
 	if err != nil {
 		return nil, err
 	}
-	return &deviceV2{
+	return &Device{
 		ID:         d.ID,
 		Platform:   d.Platform,
 		Attributes: d.Attributes,
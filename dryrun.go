@@ -0,0 +1,219 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DryRunRequest is a request built by a client in dry-run mode, instead of being sent.
+type DryRunRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Header http.Header     `json:"header"` // Header holds the request headers, without Authorization.
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// DryRunSink receives the requests of a client in dry-run mode. ctx is the context of the call.
+type DryRunSink func(ctx context.Context, req DryRunRequest)
+
+// LogSink logs dry-run requests to l, or to the standard logger when l is nil.
+func LogSink(l *log.Logger) DryRunSink {
+	if l == nil {
+		l = log.Default()
+	}
+	return func(ctx context.Context, req DryRunRequest) {
+		l.Printf("customerio dry run: %s %s %s", req.Method, req.URL, req.Body)
+	}
+}
+
+// WriterSink writes dry-run requests to w as JSON, one per line. Write errors are ignored.
+func WriterSink(w io.Writer) DryRunSink {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(ctx context.Context, req DryRunRequest) {
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(req)
+	}
+}
+
+// ChanSink sends dry-run requests to ch. A call blocks until its request is received
+// or its context is done.
+func ChanSink(ch chan<- DryRunRequest) DryRunSink {
+	return func(ctx context.Context, req DryRunRequest) {
+		select {
+		case ch <- req:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// WithDryRun makes the track client hand its requests to sink instead of sending them.
+// Calls succeed without any network access; middleware and observers still see the requests.
+func WithDryRun(sink DryRunSink) Option {
+	return Option{
+		api: func(a *APIClient) {},
+		track: func(c *CustomerIO) {
+			c.dryRun = sink
+		},
+	}
+}
+
+// dryRunRoundTrip answers every request with an empty 200 response after handing it to sink.
+func dryRunRoundTrip(sink DryRunSink) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		r := DryRunRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+		}
+		r.Header.Del("Authorization")
+		if req.Body != nil {
+			b, err := ioutil.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			if len(b) > 0 {
+				r.Body = b
+			}
+		}
+		sink(req.Context(), r)
+
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader("{}")),
+			Request:    req,
+		}, nil
+	}
+}
+
+// ErrShadowDropped is reported to ShadowConfig.OnError for the copies dropped because
+// ShadowConfig.MaxInFlight copies were already being sent.
+var ErrShadowDropped = errors.New("shadow copy dropped, too many copies in flight")
+
+// ShadowConfig configures WithShadow. Zero values take the defaults: at most 100 copies
+// in flight, each sent within 10s.
+type ShadowConfig struct {
+	// MaxInFlight is the number of copies that can be sent at once. Copies made while
+	// that many are in flight are dropped. Defaults to 100.
+	MaxInFlight int
+	// Timeout bounds the time spent sending a copy, retries included. Defaults to 10s.
+	Timeout time.Duration
+	// OnError, when not nil, is called with the error of every copy that failed, from a
+	// background goroutine, or was dropped, from the goroutine making the call.
+	OnError func(error)
+}
+
+// shadow is a secondary track client receiving a copy of every request.
+type shadow struct {
+	client *CustomerIO
+	config ShadowConfig
+
+	mu       sync.Mutex
+	inFlight int
+	idle     chan struct{} // idle is closed while no copy is in flight.
+}
+
+// WithShadow sends a copy of every request of the track client to another track client,
+// typically set up for a secondary workspace, in parallel. The copies are sent in the
+// background and do not affect the outcome of the calls. Use FlushShadow to wait for
+// the copies in flight, e.g. before exiting. No copy is sent by a client in dry-run mode.
+func WithShadow(client *CustomerIO, config ShadowConfig) Option {
+	if config.MaxInFlight <= 0 {
+		config.MaxInFlight = 100
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return Option{
+		api: func(a *APIClient) {},
+		track: func(c *CustomerIO) {
+			idle := make(chan struct{})
+			close(idle)
+			c.shadow = &shadow{client: client, config: config, idle: idle}
+		},
+	}
+}
+
+// FlushShadow waits until no copy to the client set with WithShadow is in flight,
+// or ctx is done. It returns immediately when no shadow is set.
+func (c *CustomerIO) FlushShadow(ctx context.Context) error {
+	if c.shadow == nil {
+		return nil
+	}
+	c.shadow.mu.Lock()
+	idle := c.shadow.idle
+	c.shadow.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// send sends a copy of a request to the shadow client. url is the url of the request
+// for the primary client, whose base URL is replaced with the one of the shadow.
+func (s *shadow) send(base, method, url string, body []byte) {
+	s.mu.Lock()
+	if s.inFlight >= s.config.MaxInFlight {
+		s.mu.Unlock()
+		s.report(ErrShadowDropped)
+		return
+	}
+	if s.inFlight == 0 {
+		s.idle = make(chan struct{})
+	}
+	s.inFlight++
+	s.mu.Unlock()
+
+	c := s.client
+	go func() {
+		defer s.done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+		defer cancel()
+
+		shadowURL := c.URL + strings.TrimPrefix(url, base)
+		resp, respBody, err := doHTTP(ctx, c.Client, c.URL, c.httpConfig, func() (*http.Request, error) {
+			return c.newRequest(ctx, method, shadowURL, body)
+		})
+		if err == nil && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+			err = newCustomerIOError(method, shadowURL, resp, respBody)
+		}
+		if err != nil {
+			s.report(err)
+		}
+	}()
+}
+
+// done records the end of a copy, marking the shadow idle after the last one.
+func (s *shadow) done() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	if s.inFlight == 0 {
+		close(s.idle)
+	}
+}
+
+func (s *shadow) report(err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(err)
+	}
+}
@@ -0,0 +1,284 @@
+package customerio_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestWithDryRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("request should not be sent")
+	}))
+	defer srv.Close()
+
+	ch := make(chan customerio.DryRunRequest, 3)
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithDryRun(customerio.ChanSink(ch)))
+	track.URL = srv.URL
+
+	ctx := context.Background()
+	if err := track.IdentifyCtx(ctx, "1", map[string]interface{}{"plan": "basic"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.TrackCtx(ctx, "1", "purchase", map[string]interface{}{"price": 10}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.AddDeviceCtx(ctx, "1", "token", "ios", nil); err != nil {
+		t.Fatal(err)
+	}
+	close(ch)
+
+	expect := []struct {
+		method, path, body string
+	}{
+		{"PUT", "/api/v1/customers/1", `{"plan":"basic"}`},
+		{"POST", "/api/v1/customers/1/events", `{"data":{"price":10},"name":"purchase"}`},
+		{"PUT", "/api/v1/customers/1/devices", `{"device":{"id":"token","platform":"ios","attributes":null}}`},
+	}
+	i := 0
+	for req := range ch {
+		e := expect[i]
+		if req.Method != e.method || req.URL != srv.URL+e.path || string(req.Body) != e.body {
+			t.Errorf("request %d: expected %s %s %s, got %s %s %s", i, e.method, e.path, e.body, req.Method, req.URL, req.Body)
+		}
+		if req.Header.Get("Authorization") != "" {
+			t.Errorf("request %d: expected the Authorization header to be removed", i)
+		}
+		if req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request %d: expected the other headers to be kept, got: %v", i, req.Header)
+		}
+		i++
+	}
+	if i != len(expect) {
+		t.Errorf("Expected %d requests, got %d", len(expect), i)
+	}
+}
+
+func TestDryRunSinks(t *testing.T) {
+	var out, logs bytes.Buffer
+	sinks := []customerio.DryRunSink{
+		customerio.WriterSink(&out),
+		customerio.LogSink(log.New(&logs, "", 0)),
+	}
+	for _, sink := range sinks {
+		track := customerio.NewTrackClient("site_id", "api_key", customerio.WithDryRun(sink))
+		if err := track.Identify("1", map[string]interface{}{"plan": "basic"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := track.Track("1", "purchase", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got: %q", out.String())
+	}
+	var req customerio.DryRunRequest
+	if err := json.Unmarshal([]byte(lines[0]), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "PUT" || req.URL != "https://track.customer.io/api/v1/customers/1" || string(req.Body) != `{"plan":"basic"}` {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+
+	if !strings.Contains(logs.String(), `PUT https://track.customer.io/api/v1/customers/1 {"plan":"basic"}`) {
+		t.Errorf("Unexpected logs: %q", logs.String())
+	}
+}
+
+func TestWithShadow(t *testing.T) {
+	type received struct {
+		path, body, auth string
+	}
+	shadowCh := make(chan received, 2)
+	shadowSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		shadowCh <- received{req.URL.Path, string(b), req.Header.Get("Authorization")}
+		if req.URL.Path == "/api/v1/customers/2" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer shadowSrv.Close()
+	primary, _ := flakyServer(0, http.StatusOK)
+	defer primary.Close()
+
+	errs := make(chan error, 1)
+	secondary := customerio.NewTrackClient("shadow_site", "shadow_key")
+	secondary.URL = shadowSrv.URL
+
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithShadow(secondary, customerio.ShadowConfig{
+		OnError: func(err error) { errs <- err },
+	}))
+	track.URL = primary.URL
+
+	if err := track.Identify("1", map[string]interface{}{"plan": "basic"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.Identify("2", nil); err != nil {
+		t.Fatalf("Expected the shadow failure not to affect the call, got: %v", err)
+	}
+
+	seen := map[string]received{}
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-shadowCh:
+			seen[r.path] = r
+		case <-time.After(time.Second):
+			t.Fatal("Expected the shadow to receive the requests")
+		}
+	}
+	r := seen["/api/v1/customers/1"]
+	if r.body != `{"plan":"basic"}` {
+		t.Errorf("Unexpected shadow request: %+v", r)
+	}
+	if r.auth != "Basic "+base64.URLEncoding.EncodeToString([]byte("shadow_site:shadow_key")) {
+		t.Error("Expected the shadow to use its own credentials")
+	}
+
+	select {
+	case err := <-errs:
+		if !customerio.IsTemporary(err) {
+			t.Errorf("Expected the shadow error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the shadow error to be reported")
+	}
+}
+
+func TestWithShadowLimits(t *testing.T) {
+	release := make(chan struct{})
+	shadowSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer shadowSrv.Close()
+	defer close(release)
+	primary, _ := flakyServer(0, http.StatusOK)
+	defer primary.Close()
+
+	secondary := customerio.NewTrackClient("shadow_site", "shadow_key")
+	secondary.URL = shadowSrv.URL
+
+	var mu sync.Mutex
+	var errs []error
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithShadow(secondary, customerio.ShadowConfig{
+		MaxInFlight: 2,
+		Timeout:     200 * time.Millisecond,
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	}))
+	track.URL = primary.URL
+
+	for i := 0; i < 3; i++ {
+		if err := track.Identify("1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := track.FlushShadow(ctx); err != nil {
+		t.Fatalf("Expected the copies to time out, got: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var dropped, timedOut int
+	for _, err := range errs {
+		switch {
+		case errors.Is(err, customerio.ErrShadowDropped):
+			dropped++
+		case errors.Is(err, context.DeadlineExceeded):
+			timedOut++
+		}
+	}
+	if dropped != 1 || timedOut != 2 {
+		t.Errorf("Expected 1 dropped and 2 timed out copies, got: %v", errs)
+	}
+}
+
+func TestFlushShadowConcurrent(t *testing.T) {
+	shadowSrv, _ := flakyServer(0, http.StatusOK)
+	defer shadowSrv.Close()
+	primary, _ := flakyServer(0, http.StatusOK)
+	defer primary.Close()
+
+	secondary := customerio.NewTrackClient("shadow_site", "shadow_key")
+	secondary.URL = shadowSrv.URL
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithShadow(secondary, customerio.ShadowConfig{}))
+	track.URL = primary.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if err := track.Identify("1", nil); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for flushing := true; flushing; {
+		select {
+		case <-done:
+			flushing = false
+		default:
+		}
+		if err := track.FlushShadow(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWithShadowDryRun(t *testing.T) {
+	shadowSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Errorf("Unexpected shadow request: %s %s", req.Method, req.URL.Path)
+	}))
+	defer shadowSrv.Close()
+
+	secondary := customerio.NewTrackClient("shadow_site", "shadow_key")
+	secondary.URL = shadowSrv.URL
+	ch := make(chan customerio.DryRunRequest, 1)
+	track := customerio.NewTrackClient("site_id", "api_key",
+		customerio.WithDryRun(customerio.ChanSink(ch)),
+		customerio.WithShadow(secondary, customerio.ShadowConfig{}),
+	)
+
+	if err := track.Identify("1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.FlushShadow(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if req := <-ch; req.URL != track.URL+"/api/v1/customers/1" {
+		t.Errorf("Unexpected dry-run request: %+v", req)
+	}
+}
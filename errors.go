@@ -0,0 +1,97 @@
+package customerio
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Errors matching API errors by category, to be used with errors.Is:
+//
+//	if errors.Is(err, customerio.ErrRateLimited) {
+//		// slow down
+//	}
+var (
+	ErrRateLimited = errors.New("rate limited")
+	ErrAuth        = errors.New("authentication failed")
+	ErrNotFound    = errors.New("not found")
+	ErrTemporary   = errors.New("temporary failure")
+)
+
+// IsRateLimited reports whether err was caused by a 429 response.
+func IsRateLimited(err error) bool { return errors.Is(err, ErrRateLimited) }
+
+// IsAuth reports whether err was caused by a 401 or 403 response.
+func IsAuth(err error) bool { return errors.Is(err, ErrAuth) }
+
+// IsNotFound reports whether err was caused by a 404 response.
+func IsNotFound(err error) bool { return errors.Is(err, ErrNotFound) }
+
+// IsTemporary reports whether err is worth retrying: a response with a
+// 408, 429, 500, 502, 503 or 504 status code, or a network timeout.
+func IsTemporary(err error) bool {
+	if errors.Is(err, ErrTemporary) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// statusIs implements errors.Is for API errors carrying the given status code.
+func statusIs(status int, target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return status == http.StatusTooManyRequests
+	case ErrAuth:
+		return status == http.StatusUnauthorized || status == http.StatusForbidden
+	case ErrNotFound:
+		return status == http.StatusNotFound
+	case ErrTemporary:
+		return retryableStatus(status)
+	}
+	return false
+}
+
+func requestID(h http.Header) string {
+	if h == nil {
+		return ""
+	}
+	if id := h.Get("X-Request-Id"); id != "" {
+		return id
+	}
+	return h.Get("X-Amzn-Requestid")
+}
+
+// parseErrorMessages extracts the messages from the error payloads returned by Customer.io:
+// {"meta": {"error": "..."}}, {"meta": {"errors": ["..."]}} and {"errors": [{"detail": "..."}]}.
+func parseErrorMessages(body []byte) []string {
+	var payload struct {
+		Meta struct {
+			Error  string   `json:"error"`
+			Errors []string `json:"errors"`
+		} `json:"meta"`
+		Errors []struct {
+			Detail  string `json:"detail"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &payload) != nil {
+		return nil
+	}
+
+	var messages []string
+	if payload.Meta.Error != "" {
+		messages = append(messages, payload.Meta.Error)
+	}
+	messages = append(messages, payload.Meta.Errors...)
+	for _, e := range payload.Errors {
+		switch {
+		case e.Detail != "":
+			messages = append(messages, e.Detail)
+		case e.Message != "":
+			messages = append(messages, e.Message)
+		}
+	}
+	return messages
+}
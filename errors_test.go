@@ -0,0 +1,166 @@
+package customerio_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestCustomerIOErrorAccessors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"meta": {"errors": ["too many requests", "slow down"]}}`))
+	}))
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("site_id", "api_key")
+	track.URL = srv.URL
+
+	err := track.Track("1", "purchase", nil)
+
+	var cioErr *customerio.CustomerIOError
+	if !errors.As(err, &cioErr) {
+		t.Fatalf("expected CustomerIOError, got: %#v", err)
+	}
+	if cioErr.StatusCode() != http.StatusTooManyRequests {
+		t.Errorf("wrong status. got: %d", cioErr.StatusCode())
+	}
+	if cioErr.Method() != "POST" {
+		t.Errorf("wrong method. got: %s", cioErr.Method())
+	}
+	if cioErr.URL() != srv.URL+"/api/v1/customers/1/events" {
+		t.Errorf("wrong url. got: %s", cioErr.URL())
+	}
+	if !reflect.DeepEqual(cioErr.Messages(), []string{"too many requests", "slow down"}) {
+		t.Errorf("wrong messages. got: %#v", cioErr.Messages())
+	}
+	if cioErr.RetryAfter() != 30*time.Second {
+		t.Errorf("wrong retry after. got: %s", cioErr.RetryAfter())
+	}
+	if cioErr.RequestID() != "req-1" {
+		t.Errorf("wrong request id. got: %s", cioErr.RequestID())
+	}
+
+	if !customerio.IsRateLimited(err) || !customerio.IsTemporary(err) {
+		t.Error("expected rate limited, temporary error")
+	}
+	if customerio.IsAuth(err) || customerio.IsNotFound(err) {
+		t.Error("unexpected error category")
+	}
+}
+
+func TestErrorCategories(t *testing.T) {
+	cases := []struct {
+		status int
+		target error
+	}{
+		{http.StatusUnauthorized, customerio.ErrAuth},
+		{http.StatusForbidden, customerio.ErrAuth},
+		{http.StatusNotFound, customerio.ErrNotFound},
+		{http.StatusTooManyRequests, customerio.ErrRateLimited},
+		{http.StatusServiceUnavailable, customerio.ErrTemporary},
+	}
+
+	for _, c := range cases {
+		status := c.status
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"meta": {"error": "failed"}}`))
+		}))
+
+		track := customerio.NewTrackClient("site_id", "api_key")
+		track.URL = srv.URL
+		if err := track.Delete("1"); !errors.Is(err, c.target) {
+			t.Errorf("track %d: expected %v, got: %#v", status, c.target, err)
+		}
+
+		api := customerio.NewAPIClient("myKey")
+		api.URL = srv.URL
+		_, err := api.SendEmail(context.Background(), &customerio.SendEmailRequest{
+			TransactionalMessageID: "1",
+			Identifiers:            map[string]string{"id": "1"},
+			To:                     "customer@example.com",
+		})
+		if !errors.Is(err, c.target) {
+			t.Errorf("transactional %d: expected %v, got: %#v", status, c.target, err)
+		}
+
+		srv.Close()
+	}
+
+	if customerio.IsTemporary(errors.New("boom")) {
+		t.Error("plain errors are not temporary")
+	}
+}
+
+func TestTransactionalErrorUnwrap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"meta": {"error": "missing to"}}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	_, err := api.SendPush(context.Background(), &customerio.SendPushRequest{
+		TransactionalMessageID: "1",
+		Identifiers:            map[string]string{"id": "1"},
+	})
+
+	var txErr *customerio.TransactionalError
+	if !errors.As(err, &txErr) || txErr.Err != "missing to" {
+		t.Fatalf("Expected TransactionalError, got: %#v", err)
+	}
+	var apiErr *customerio.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got: %#v", err)
+	}
+	if apiErr.Endpoint != "/v1/send/push" || !reflect.DeepEqual(apiErr.Messages, []string{"missing to"}) {
+		t.Errorf("wrong api error: %#v", apiErr)
+	}
+}
+
+func TestAPIErrorAccessors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"meta": {"error": "slow down"}}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	_, segmentErr := api.GetSegment(context.Background(), 1)
+	_, sendErr := api.SendEmail(context.Background(), &customerio.SendEmailRequest{
+		Identifiers:            map[string]string{"id": "customer_1"},
+		TransactionalMessageID: "1",
+	})
+	var txErr *customerio.TransactionalError
+	if !errors.As(sendErr, &txErr) {
+		t.Fatalf("expected TransactionalError, got: %#v", sendErr)
+	}
+
+	for _, err := range []error{segmentErr, sendErr} {
+		var apiErr *customerio.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected APIError, got: %#v", err)
+		}
+		if apiErr.RetryAfter() != 30*time.Second {
+			t.Errorf("wrong retry after. got: %s", apiErr.RetryAfter())
+		}
+		if apiErr.RequestID() != "req-1" {
+			t.Errorf("wrong request id. got: %s", apiErr.RequestID())
+		}
+	}
+}
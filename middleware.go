@@ -0,0 +1,38 @@
+package customerio
+
+import "net/http"
+
+// RoundTripFunc sends a single HTTP request and returns its response.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of requests, for instance to log them, add headers
+// or inject faults. It must call next to actually send the request, unless it
+// answers on its own.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware wraps every HTTP attempt of a client, retries included, with the given middleware.
+// The first middleware is the outermost one: it sees the request first and the response last.
+// Several WithMiddleware options add up in the same order.
+func WithMiddleware(mw ...Middleware) Option {
+	return Option{
+		api: func(a *APIClient) {
+			a.middleware = append(a.middleware, mw...)
+		},
+		track: func(c *CustomerIO) {
+			c.middleware = append(c.middleware, mw...)
+		},
+	}
+}
+
+// chain returns the function sending requests through the middleware, then client,
+// or to the dry-run sink when one is set.
+func (cfg httpConfig) chain(client *http.Client) RoundTripFunc {
+	do := RoundTripFunc(client.Do)
+	if cfg.dryRun != nil {
+		do = dryRunRoundTrip(cfg.dryRun)
+	}
+	for i := len(cfg.middleware) - 1; i >= 0; i-- {
+		do = cfg.middleware[i](do)
+	}
+	return do
+}
@@ -0,0 +1,100 @@
+package customerio_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var headers []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		headers = append(headers, req.Header.Get("X-Signature"))
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	var order []string
+	record := func(name string) customerio.Middleware {
+		return func(next customerio.RoundTripFunc) customerio.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+" request")
+				resp, err := next(req)
+				order = append(order, name+" response")
+				return resp, err
+			}
+		}
+	}
+	sign := func(next customerio.RoundTripFunc) customerio.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Signature", req.Method+" "+req.URL.Path)
+			return next(req)
+		}
+	}
+
+	track := customerio.NewTrackClient("site_id", "api_key",
+		customerio.WithMiddleware(record("outer"), record("inner")),
+		customerio.WithMiddleware(sign))
+	track.URL = srv.URL
+	api := customerio.NewAPIClient("myKey", customerio.WithMiddleware(sign))
+	api.URL = srv.URL
+
+	if err := track.Identify("1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.GetCustomerSegments(context.Background(), "1", customerio.IDTypeID); err != nil {
+		t.Fatal(err)
+	}
+
+	expectOrder := []string{"outer request", "inner request", "inner response", "outer response"}
+	if !reflect.DeepEqual(order, expectOrder) {
+		t.Errorf("Expect: %v, Got: %v", expectOrder, order)
+	}
+	expectHeaders := []string{"PUT /api/v1/customers/1", "GET /v1/customers/1/segments"}
+	if !reflect.DeepEqual(headers, expectHeaders) {
+		t.Errorf("Expect: %v, Got: %v", expectHeaders, headers)
+	}
+}
+
+func TestWithMiddlewareFaultInjection(t *testing.T) {
+	srv, calls := flakyServer(0, http.StatusOK)
+	defer srv.Close()
+
+	// fail the first attempt without reaching the server.
+	var attempts int32
+	fault := func(next customerio.RoundTripFunc) customerio.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(strings.NewReader("injected")),
+					Request:    req,
+				}, nil
+			}
+			return next(req)
+		}
+	}
+
+	track := customerio.NewTrackClient("site_id", "api_key",
+		customerio.WithRetry(testRetryPolicy),
+		customerio.WithMiddleware(fault))
+	track.URL = srv.URL
+
+	if err := track.Track("1", "purchase", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 2)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("wrong number of requests reaching the server. got: %d, want: %d", got, 1)
+	}
+}
@@ -0,0 +1,180 @@
+package customerio
+
+import (
+	"context"
+	"fmt"
+)
+
+// Newsletter represents a newsletter object returned by the API.
+type Newsletter struct {
+	ID                  int      `json:"id"`                    // Unique identifier for the newsletter.
+	DeduplicateID       string   `json:"deduplicate_id"`        // A string in the format id:timestamp.
+	Name                string   `json:"name"`                  // Name of the newsletter.
+	Type                string   `json:"type"`                  // Type of newsletter: email, push, sms...
+	Created             int64    `json:"created"`               // Unix timestamp of the newsletter creation.
+	Updated             int64    `json:"updated"`               // Unix timestamp of the last update.
+	SentAt              int64    `json:"sent_at"`               // Unix timestamp of the send, if sent.
+	ContentIDs          []int    `json:"content_ids"`           // Contents of the newsletter: language and A/B test variants.
+	Tags                []string `json:"tags,omitempty"`        // Optional tags associated with the newsletter.
+	RecipientSegmentIDs []int    `json:"recipient_segment_ids"` // Segments the newsletter was sent to.
+}
+
+// NewsletterContent represents a variant of a newsletter, for a language or an A/B test.
+type NewsletterContent struct {
+	ID            int    `json:"id"`             // Unique identifier for the content.
+	NewsletterID  int    `json:"newsletter_id"`  // Newsletter the content belongs to.
+	DeduplicateID string `json:"deduplicate_id"` // A string in the format id:timestamp.
+	Name          string `json:"name"`           // Name of the variant.
+	Type          string `json:"type"`           // Type of message: email, push, sms...
+	Language      string `json:"language"`       // Language of the variant, if any.
+	Layout        string `json:"layout"`         // Layout used by the message, for emails.
+	From          string `json:"from"`           // Sender of the message.
+	ReplyTo       string `json:"reply_to"`       // Reply-to address of the message.
+	Recipient     string `json:"recipient"`      // Recipient of the message.
+	Subject       string `json:"subject"`        // Subject of the message.
+	PreheaderText string `json:"preheader_text"` // Preheader of the message, for emails.
+	Body          string `json:"body"`           // Body of the message.
+	Created       int64  `json:"created"`        // Unix timestamp of the content creation.
+	Updated       int64  `json:"updated"`        // Unix timestamp of the last update.
+}
+
+// NewsletterLink is a link found in a newsletter.
+type NewsletterLink struct {
+	ID   int    `json:"id"`   // Unique identifier for the link.
+	Href string `json:"href"` // Target of the link.
+}
+
+// NewsletterLinkMetrics holds the click metrics of a newsletter link.
+type NewsletterLinkMetrics struct {
+	Link   NewsletterLink `json:"link"`   // The link.
+	Metric Metrics        `json:"metric"` // Clicks per period.
+}
+
+// ListNewslettersResponse represents a page of newsletters.
+type ListNewslettersResponse struct {
+	Newsletters []Newsletter `json:"newsletters"` // List of newsletters.
+	Next        string       `json:"next"`        // Optional pagination cursor.
+}
+
+// ListNewsletters retrieves a page of newsletters. Use PageStart, PageLimit and SortOrder to paginate,
+// or Newsletters to iterate over all of them.
+func (c *APIClient) ListNewsletters(ctx context.Context, opts ...ListOption) (*ListNewslettersResponse, error) {
+	var response ListNewslettersResponse
+	if err := c.getJSON(ctx, withQuery("/v1/newsletters", listQuery(nil, opts)), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetNewsletterResponse represents the response for retrieving a single newsletter.
+type GetNewsletterResponse struct {
+	Newsletter Newsletter `json:"newsletter"` // The requested newsletter.
+}
+
+// GetNewsletter retrieves a specific newsletter by its ID.
+func (c *APIClient) GetNewsletter(ctx context.Context, newsletterID int) (*GetNewsletterResponse, error) {
+	var response GetNewsletterResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/v1/newsletters/%d", newsletterID), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ListNewsletterContentsResponse represents the variants of a newsletter.
+type ListNewsletterContentsResponse struct {
+	Contents []NewsletterContent `json:"contents"` // List of contents.
+}
+
+// ListNewsletterContents retrieves the language and A/B test variants of a newsletter.
+func (c *APIClient) ListNewsletterContents(ctx context.Context, newsletterID int) (*ListNewsletterContentsResponse, error) {
+	var response ListNewsletterContentsResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/v1/newsletters/%d/contents", newsletterID), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetNewsletterContentResponse represents the response for retrieving a single newsletter variant.
+type GetNewsletterContentResponse struct {
+	Content NewsletterContent `json:"content"` // The requested content.
+}
+
+// GetNewsletterContent retrieves a specific variant of a newsletter.
+func (c *APIClient) GetNewsletterContent(ctx context.Context, newsletterID, contentID int) (*GetNewsletterContentResponse, error) {
+	var response GetNewsletterContentResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/v1/newsletters/%d/contents/%d", newsletterID, contentID), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetNewsletterMetrics returns the metrics of a newsletter over time. req may be nil to use the API defaults.
+func (c *APIClient) GetNewsletterMetrics(ctx context.Context, newsletterID int, req *MetricsRequest) (*GetMetricsResponse, error) {
+	var response GetMetricsResponse
+	path := withQuery(fmt.Sprintf("/v1/newsletters/%d/metrics", newsletterID), req.query())
+	if err := c.getJSON(ctx, path, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetNewsletterLinkMetricsResponse represents the click metrics of the links of a newsletter.
+type GetNewsletterLinkMetricsResponse struct {
+	Links []NewsletterLinkMetrics `json:"links"` // Metrics per link.
+}
+
+// GetNewsletterLinkMetrics returns the click metrics of each link of a newsletter. req may be nil to use the API defaults.
+func (c *APIClient) GetNewsletterLinkMetrics(ctx context.Context, newsletterID int, req *MetricsRequest) (*GetNewsletterLinkMetricsResponse, error) {
+	var response GetNewsletterLinkMetricsResponse
+	path := withQuery(fmt.Sprintf("/v1/newsletters/%d/metrics/links", newsletterID), req.query())
+	if err := c.getJSON(ctx, path, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// NewsletterIterator iterates over newsletters, fetching pages as needed.
+type NewsletterIterator struct {
+	pager
+	page []Newsletter
+	cur  Newsletter
+}
+
+// Newsletters returns an iterator over all the newsletters, following the pagination
+// cursor until the results are exhausted or ctx is done.
+// PageLimit sets the size of the pages requested and SortOrder their order.
+func (c *APIClient) Newsletters(ctx context.Context, opts ...ListOption) *NewsletterIterator {
+	it := &NewsletterIterator{}
+	it.pager = pager{
+		ctx:  ctx,
+		opts: opts,
+		fetch: func(opts []ListOption) (string, int, error) {
+			resp, err := c.ListNewsletters(ctx, opts...)
+			if err != nil {
+				return "", 0, err
+			}
+			it.page = resp.Newsletters
+			return resp.Next, len(it.page), nil
+		},
+	}
+	return it
+}
+
+// Next advances to the next newsletter, returning false when there are no more newsletters or an error occurred.
+func (it *NewsletterIterator) Next() bool {
+	if len(it.page) == 0 && !it.more() {
+		return false
+	}
+	it.cur, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Newsletter returns the current newsletter.
+func (it *NewsletterIterator) Newsletter() Newsletter {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *NewsletterIterator) Err() error {
+	return it.err
+}
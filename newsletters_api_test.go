@@ -0,0 +1,129 @@
+package customerio_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func newslettersAppServer(t *testing.T) (*customerio.APIClient, *httptest.Server) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.RequestURI() {
+		case "/v1/newsletters?limit=2&sort=desc":
+			w.Write([]byte(`{"newsletters": [{"id": 1, "name": "Weekly #1"}, {"id": 2, "name": "Weekly #2"}], "next": "p2"}`))
+		case "/v1/newsletters?limit=2&sort=desc&start=p2":
+			w.Write([]byte(`{"newsletters": [{"id": 3, "name": "Weekly #3"}], "next": ""}`))
+		case "/v1/newsletters/1":
+			w.Write([]byte(`{"newsletter": {"id": 1, "name": "Weekly #1", "type": "email", "content_ids": [10, 11], "sent_at": 1600000000}}`))
+		case "/v1/newsletters/1/contents":
+			w.Write([]byte(`{"contents": [{"id": 10, "newsletter_id": 1, "language": "fr", "subject": "Bonjour"}]}`))
+		case "/v1/newsletters/1/contents/10":
+			w.Write([]byte(`{"content": {"id": 10, "newsletter_id": 1, "language": "fr", "subject": "Bonjour"}}`))
+		case "/v1/newsletters/1/metrics?period=weeks&steps=1":
+			w.Write([]byte(`{"metric": {"series": {"sent": [100], "opened": [40]}}}`))
+		case "/v1/newsletters/1/metrics/links?unique=true":
+			w.Write([]byte(`{"links": [{"link": {"id": 5, "href": "https://example.com"}, "metric": {"series": {"clicked": [7]}}}]}`))
+		default:
+			t.Errorf("Unexpected request: %s %s", req.Method, req.URL.RequestURI())
+		}
+	}))
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	return api, srv
+}
+
+func TestNewsletters(t *testing.T) {
+	api, srv := newslettersAppServer(t)
+	defer srv.Close()
+
+	var names []string
+	it := api.Newsletters(context.Background(), customerio.PageLimit(2), customerio.SortOrder("desc"))
+	for it.Next() {
+		names = append(names, it.Newsletter().Name)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"Weekly #1", "Weekly #2", "Weekly #3"}
+	if !reflect.DeepEqual(names, expect) {
+		t.Errorf("Expect: %v, Got: %v", expect, names)
+	}
+}
+
+func TestGetNewsletter(t *testing.T) {
+	api, srv := newslettersAppServer(t)
+	defer srv.Close()
+
+	resp, err := api.GetNewsletter(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.GetNewsletterResponse{
+		Newsletter: customerio.Newsletter{ID: 1, Name: "Weekly #1", Type: "email", ContentIDs: []int{10, 11}, SentAt: 1600000000},
+	}
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+}
+
+func TestNewsletterContents(t *testing.T) {
+	api, srv := newslettersAppServer(t)
+	defer srv.Close()
+
+	content := customerio.NewsletterContent{ID: 10, NewsletterID: 1, Language: "fr", Subject: "Bonjour"}
+
+	list, err := api.ListNewsletterContents(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (&customerio.ListNewsletterContentsResponse{Contents: []customerio.NewsletterContent{content}}); !reflect.DeepEqual(list, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, list)
+	}
+
+	single, err := api.GetNewsletterContent(context.Background(), 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (&customerio.GetNewsletterContentResponse{Content: content}); !reflect.DeepEqual(single, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, single)
+	}
+}
+
+func TestNewsletterMetrics(t *testing.T) {
+	api, srv := newslettersAppServer(t)
+	defer srv.Close()
+
+	metrics, err := api.GetNewsletterMetrics(context.Background(), 1, &customerio.MetricsRequest{
+		Period: customerio.MetricsPeriodWeeks,
+		Steps:  1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.GetMetricsResponse{
+		Metric: customerio.Metrics{Series: customerio.MetricSeries{Sent: []int{100}, Opened: []int{40}}},
+	}
+	if !reflect.DeepEqual(metrics, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, metrics)
+	}
+
+	links, err := api.GetNewsletterLinkMetrics(context.Background(), 1, &customerio.MetricsRequest{Unique: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectLinks := &customerio.GetNewsletterLinkMetricsResponse{
+		Links: []customerio.NewsletterLinkMetrics{{
+			Link:   customerio.NewsletterLink{ID: 5, Href: "https://example.com"},
+			Metric: customerio.Metrics{Series: customerio.MetricSeries{Clicked: []int{7}}},
+		}},
+	}
+	if !reflect.DeepEqual(links, expectLinks) {
+		t.Errorf("Expect: %#v, Got: %#v", expectLinks, links)
+	}
+}
@@ -0,0 +1,126 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ObjectType identifies a type of object, such as companies or accounts.
+// It is the id of the object type in Customer.io, e.g. "1".
+type ObjectType string
+
+// ObjectID identifies an object within its type, e.g. "acme".
+type ObjectID string
+
+// Relationship relates a person to an object.
+type Relationship struct {
+	ObjectType ObjectType
+	ObjectID   ObjectID
+	// Attributes describe the relationship, e.g. the role of a person in an account.
+	Attributes map[string]interface{}
+}
+
+type relationship struct {
+	Identifiers map[string]string      `json:"identifiers"`
+	Attributes  map[string]interface{} `json:"relationship_attributes,omitempty"`
+}
+
+// MarshalJSON encodes the relationship as expected by the v2 API.
+func (r Relationship) MarshalJSON() ([]byte, error) {
+	return json.Marshal(relationship{
+		Identifiers: objectIdentifiers(r.ObjectType, r.ObjectID),
+		Attributes:  r.Attributes,
+	})
+}
+
+// UnmarshalJSON decodes a relationship encoded by MarshalJSON.
+func (r *Relationship) UnmarshalJSON(b []byte) error {
+	var rel relationship
+	if err := json.Unmarshal(b, &rel); err != nil {
+		return err
+	}
+	*r = Relationship{
+		ObjectType: ObjectType(rel.Identifiers["object_type_id"]),
+		ObjectID:   ObjectID(rel.Identifiers["object_id"]),
+		Attributes: rel.Attributes,
+	}
+	return nil
+}
+
+func objectIdentifiers(objectType ObjectType, objectID ObjectID) map[string]string {
+	return map[string]string{
+		"object_type_id": string(objectType),
+		"object_id":      string(objectID),
+	}
+}
+
+// BatchIdentifyObject creates or updates an object and sets its attributes.
+func BatchIdentifyObject(objectType ObjectType, objectID ObjectID, attributes map[string]interface{}) BatchItem {
+	return BatchItem{
+		Type:        "object",
+		Action:      "identify",
+		Identifiers: objectIdentifiers(objectType, objectID),
+		Attributes:  attributes,
+	}
+}
+
+// BatchDeleteObject deletes an object.
+func BatchDeleteObject(objectType ObjectType, objectID ObjectID) BatchItem {
+	return BatchItem{
+		Type:        "object",
+		Action:      "delete",
+		Identifiers: objectIdentifiers(objectType, objectID),
+	}
+}
+
+// BatchAddRelationships relates a person to objects.
+func BatchAddRelationships(id Identifier, relationships ...Relationship) BatchItem {
+	item := personItem("add_relationships", id)
+	item.Relationships = relationships
+	return item
+}
+
+// BatchDeleteRelationships removes relationships between a person and objects.
+// The attributes of relationships are ignored.
+func BatchDeleteRelationships(id Identifier, relationships ...Relationship) BatchItem {
+	item := personItem("delete_relationships", id)
+	for _, r := range relationships {
+		item.Relationships = append(item.Relationships, Relationship{ObjectType: r.ObjectType, ObjectID: r.ObjectID})
+	}
+	return item
+}
+
+// IdentifyObject creates or updates an object and sets its attributes.
+func (c *CustomerIO) IdentifyObject(ctx context.Context, objectType ObjectType, objectID ObjectID, attributes map[string]interface{}) error {
+	return c.entity(ctx, BatchIdentifyObject(objectType, objectID, attributes))
+}
+
+// DeleteObject deletes an object. Its relationships with people are removed as well.
+func (c *CustomerIO) DeleteObject(ctx context.Context, objectType ObjectType, objectID ObjectID) error {
+	return c.entity(ctx, BatchDeleteObject(objectType, objectID))
+}
+
+// AddRelationships relates a person to one or more objects, with optional relationship attributes.
+func (c *CustomerIO) AddRelationships(ctx context.Context, id Identifier, relationships ...Relationship) error {
+	if id.validate() != nil {
+		return ParamError{Param: "id"}
+	}
+	return c.entity(ctx, BatchAddRelationships(id, relationships...))
+}
+
+// DeleteRelationships removes the relationships between a person and one or more objects.
+func (c *CustomerIO) DeleteRelationships(ctx context.Context, id Identifier, relationships ...Relationship) error {
+	if id.validate() != nil {
+		return ParamError{Param: "id"}
+	}
+	return c.entity(ctx, BatchDeleteRelationships(id, relationships...))
+}
+
+// entity sends a single operation to the v2 entity endpoint.
+func (c *CustomerIO) entity(ctx context.Context, item BatchItem) error {
+	if err := item.validate(); err != nil {
+		return err
+	}
+	return c.request(ctx, "POST", fmt.Sprintf("%s/api/v2/entity", c.URL), item)
+}
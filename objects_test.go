@@ -0,0 +1,90 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestObjects(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" || req.URL.Path != "/api/v2/entity" {
+			t.Errorf("Unexpected request: %s %s", req.Method, req.URL.Path)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		bodies = append(bodies, body)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("site_id", "api_key")
+	track.URL = srv.URL
+	ctx := context.Background()
+	person := customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}
+	account := customerio.Relationship{ObjectType: "1", ObjectID: "acme", Attributes: map[string]interface{}{"role": "admin"}}
+
+	if err := track.IdentifyObject(ctx, "1", "acme", map[string]interface{}{"plan": "enterprise"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.AddRelationships(ctx, person, account); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.DeleteRelationships(ctx, person, account); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.DeleteObject(ctx, "1", "acme"); err != nil {
+		t.Fatal(err)
+	}
+
+	object := map[string]interface{}{"object_type_id": "1", "object_id": "acme"}
+	expect := []map[string]interface{}{
+		{"type": "object", "action": "identify", "identifiers": object, "attributes": map[string]interface{}{"plan": "enterprise"}},
+		{"type": "person", "action": "add_relationships", "identifiers": map[string]interface{}{"id": "1"}, "cio_relationships": []interface{}{
+			map[string]interface{}{"identifiers": object, "relationship_attributes": map[string]interface{}{"role": "admin"}},
+		}},
+		{"type": "person", "action": "delete_relationships", "identifiers": map[string]interface{}{"id": "1"}, "cio_relationships": []interface{}{
+			map[string]interface{}{"identifiers": object},
+		}},
+		{"type": "object", "action": "delete", "identifiers": object},
+	}
+	if !reflect.DeepEqual(bodies, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, bodies)
+	}
+}
+
+func TestObjectsInvalidParams(t *testing.T) {
+	track := customerio.NewTrackClient("site_id", "api_key")
+	track.URL = "http://invalid"
+	ctx := context.Background()
+	person := customerio.Identifier{Type: customerio.IdentifierTypeID, Value: "1"}
+
+	checkParamError(t, track.IdentifyObject(ctx, "", "acme", nil), "identifiers")
+	checkParamError(t, track.DeleteObject(ctx, "1", ""), "identifiers")
+	checkParamError(t, track.AddRelationships(ctx, customerio.Identifier{}, customerio.Relationship{ObjectType: "1", ObjectID: "acme"}), "id")
+	checkParamError(t, track.AddRelationships(ctx, person), "relationships")
+	checkParamError(t, track.DeleteRelationships(ctx, person, customerio.Relationship{ObjectType: "1"}), "relationships")
+}
+
+func TestRelationshipJSON(t *testing.T) {
+	in := customerio.Relationship{ObjectType: "1", ObjectID: "acme", Attributes: map[string]interface{}{"role": "admin"}}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out customerio.Relationship
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Expect: %#v, Got: %#v", in, out)
+	}
+}
@@ -0,0 +1,182 @@
+package customerio
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestInfo describes an HTTP attempt to an Observer.
+type RequestInfo struct {
+	// Method is the http method of the request.
+	Method string
+	// Route is the template of the request path relative to the base URL of the client,
+	// e.g. /api/v1/customers/{id}/events, or "other" for paths the client does not know.
+	Route string
+	// Attempt is 1 for the first attempt of a call and increases with every retry.
+	Attempt int
+}
+
+// RequestResult describes the outcome of an HTTP attempt to an Observer.
+type RequestResult struct {
+	// StatusCode is the http status code of the response, 0 when none was received.
+	StatusCode int
+	// Duration is the time spent sending the request and reading the response.
+	Duration time.Duration
+	// Err is the network error that prevented getting a response, if any.
+	// Responses with an error status code have a nil Err.
+	Err error
+}
+
+// Observer is notified of every HTTP attempt made by a client, retries included,
+// for instance to record metrics or traces.
+// Its methods are called from the goroutine making the call and must be safe for concurrent use.
+type Observer interface {
+	// OnRequestStart is called before an attempt is sent. The returned context is used
+	// for the request and passed to OnRequestEnd, which lets tracers carry a span along.
+	OnRequestStart(ctx context.Context, info RequestInfo) context.Context
+	// OnRequestEnd is called once the attempt completed.
+	OnRequestEnd(ctx context.Context, info RequestInfo, result RequestResult)
+}
+
+// WithObserver notifies o of every HTTP attempt made by a client.
+func WithObserver(o Observer) Option {
+	return Option{
+		api: func(a *APIClient) {
+			a.observer = o
+		},
+		track: func(c *CustomerIO) {
+			c.observer = o
+		},
+	}
+}
+
+// routes lists the templates of the paths called by the clients.
+// Placeholders match a single path segment.
+var routes = []string{
+	"/api/v1/customers/{id}",
+	"/api/v1/customers/{id}/events",
+	"/api/v1/customers/{id}/devices",
+	"/api/v1/customers/{id}/devices/{device_id}",
+	"/api/v1/events",
+	"/api/v1/merge_customers",
+	"/api/v1/segments/{segment_id}/add_customers",
+	"/api/v1/segments/{segment_id}/remove_customers",
+	"/api/v2/batch",
+	"/api/v2/entity",
+	"/v1/campaigns",
+	"/v1/campaigns/{campaign_id}",
+	"/v1/campaigns/{campaign_id}/actions",
+	"/v1/campaigns/{campaign_id}/actions/{action_id}",
+	"/v1/campaigns/{campaign_id}/actions/{action_id}/metrics",
+	"/v1/campaigns/{campaign_id}/metrics",
+	"/v1/campaigns/{broadcast_id}/triggers",
+	"/v1/campaigns/{broadcast_id}/triggers/{trigger_id}",
+	"/v1/campaigns/{broadcast_id}/triggers/{trigger_id}/errors",
+	"/v1/customers",
+	"/v1/customers/{id}/{resource}",
+	"/v1/newsletters",
+	"/v1/newsletters/{newsletter_id}",
+	"/v1/newsletters/{newsletter_id}/contents",
+	"/v1/newsletters/{newsletter_id}/contents/{content_id}",
+	"/v1/newsletters/{newsletter_id}/metrics",
+	"/v1/newsletters/{newsletter_id}/metrics/links",
+	"/v1/segments",
+	"/v1/segments/{segment_id}",
+	"/v1/segments/{segment_id}/customer_count",
+	"/v1/segments/{segment_id}/membership",
+	"/v1/segments/{segment_id}/used_by",
+	"/v1/send/{type}",
+	"/v1/transactional",
+	"/v1/transactional/{transactional_message_id}",
+	"/v1/transactional/{transactional_message_id}/contents",
+	"/v1/transactional/{transactional_message_id}/content/{content_id}",
+}
+
+// otherRoute is the route of the paths matching none of routes. Paths are not reported
+// as is since they hold ids, which would make for an unbounded number of routes.
+const otherRoute = "other"
+
+// routeTemplate returns the template of routes matching path once the path of base,
+// the base URL of the client, is removed from it, or otherRoute when none does.
+func routeTemplate(base, path string) string {
+	if u, err := url.Parse(base); err == nil {
+		path = strings.TrimPrefix(path, strings.TrimRight(u.EscapedPath(), "/"))
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+next:
+	for _, route := range routes {
+		parts := strings.Split(strings.Trim(route, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		for i, part := range parts {
+			if strings.HasPrefix(part, "{") {
+				if segments[i] == "" {
+					continue next
+				}
+			} else if part != segments[i] {
+				continue next
+			}
+		}
+		return route
+	}
+	return otherRoute
+}
+
+// ExpvarObserver is an Observer publishing request counts and durations with the expvar package.
+// It publishes a map with an entry per method and route, e.g. "POST /api/v1/customers/{id}/events",
+// holding:
+//
+//	requests          the number of attempts
+//	retries           the number of attempts that were retries
+//	errors            the number of attempts that failed without a response
+//	2xx, 4xx, 5xx...  the number of responses per status class
+//	duration_seconds  the total duration of the attempts
+type ExpvarObserver struct {
+	vars *expvar.Map
+	mu   sync.Mutex
+}
+
+// NewExpvarObserver publishes the metrics of an ExpvarObserver under name.
+// Like expvar.Publish, it panics if name is already in use.
+func NewExpvarObserver(name string) *ExpvarObserver {
+	return &ExpvarObserver{vars: expvar.NewMap(name)}
+}
+
+// OnRequestStart implements Observer.
+func (o *ExpvarObserver) OnRequestStart(ctx context.Context, info RequestInfo) context.Context {
+	return ctx
+}
+
+// OnRequestEnd implements Observer.
+func (o *ExpvarObserver) OnRequestEnd(ctx context.Context, info RequestInfo, result RequestResult) {
+	m := o.route(info.Method + " " + info.Route)
+	m.Add("requests", 1)
+	if info.Attempt > 1 {
+		m.Add("retries", 1)
+	}
+	if result.Err != nil {
+		m.Add("errors", 1)
+	} else {
+		m.Add(fmt.Sprintf("%dxx", result.StatusCode/100), 1)
+	}
+	m.AddFloat("duration_seconds", result.Duration.Seconds())
+}
+
+func (o *ExpvarObserver) route(key string) *expvar.Map {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if m, ok := o.vars.Get(key).(*expvar.Map); ok {
+		return m
+	}
+	m := new(expvar.Map)
+	o.vars.Set(key, m)
+	return m
+}
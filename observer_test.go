@@ -0,0 +1,135 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+type observerKey struct{}
+
+type recordingObserver struct {
+	mu     sync.Mutex
+	starts []customerio.RequestInfo
+	ends   []customerio.RequestResult
+	ctxOK  bool
+}
+
+func (o *recordingObserver) OnRequestStart(ctx context.Context, info customerio.RequestInfo) context.Context {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.starts = append(o.starts, info)
+	return context.WithValue(ctx, observerKey{}, info.Attempt)
+}
+
+func (o *recordingObserver) OnRequestEnd(ctx context.Context, info customerio.RequestInfo, result customerio.RequestResult) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ctxOK = ctx.Value(observerKey{}) == info.Attempt
+	o.ends = append(o.ends, result)
+}
+
+func TestWithObserver(t *testing.T) {
+	srv, _ := flakyServer(1, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	obs := &recordingObserver{}
+	var middlewareSawContext bool
+	track := customerio.NewTrackClient("site_id", "api_key",
+		customerio.WithRetry(testRetryPolicy),
+		customerio.WithObserver(obs),
+		customerio.WithMiddleware(func(next customerio.RoundTripFunc) customerio.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				middlewareSawContext = req.Context().Value(observerKey{}) != nil
+				return next(req)
+			}
+		}))
+	track.URL = srv.URL
+
+	if err := track.Track("user@example.com", "purchase", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []customerio.RequestInfo{
+		{Method: "POST", Route: "/api/v1/customers/{id}/events", Attempt: 1},
+		{Method: "POST", Route: "/api/v1/customers/{id}/events", Attempt: 2},
+	}
+	if !reflect.DeepEqual(obs.starts, expect) {
+		t.Errorf("Expect: %+v, Got: %+v", expect, obs.starts)
+	}
+	if len(obs.ends) != 2 || obs.ends[0].StatusCode != 503 || obs.ends[1].StatusCode != 200 {
+		t.Errorf("Unexpected results: %+v", obs.ends)
+	}
+	if !obs.ctxOK || !middlewareSawContext {
+		t.Error("Expected the context returned by OnRequestStart to be used")
+	}
+}
+
+func TestObserverRoutes(t *testing.T) {
+	srv, _ := flakyServer(0, http.StatusOK)
+	defer srv.Close()
+
+	obs := &recordingObserver{}
+	api := customerio.NewAPIClient("myKey", customerio.WithObserver(obs))
+	// routes are relative to the base URL, here behind a proxy path.
+	api.URL = srv.URL + "/proxy/customerio"
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithObserver(obs))
+	track.URL = srv.URL
+
+	ctx := context.Background()
+	api.GetCampaignAction(ctx, 1, 2)
+	api.GetCustomerAttributes(ctx, "a/b", customerio.IDTypeID)
+	api.GetTransactionalMessage(ctx, "receipt")
+	track.AddDevice("1", "device/1", "ios", nil)
+	track.TrackAnonymous("anon", "visit", nil)
+
+	var routes []string
+	for _, info := range obs.starts {
+		routes = append(routes, info.Method+" "+info.Route)
+	}
+	expect := []string{
+		"GET /v1/campaigns/{campaign_id}/actions/{action_id}",
+		"GET /v1/customers/{id}/{resource}",
+		"GET /v1/transactional/{transactional_message_id}",
+		"PUT /api/v1/customers/{id}/devices",
+		"POST /api/v1/events",
+	}
+	if !reflect.DeepEqual(routes, expect) {
+		t.Errorf("Expect: %v, Got: %v", expect, routes)
+	}
+}
+
+// expvarRuns makes the expvar names of the tests unique, so that they can run more than once.
+var expvarRuns int32
+
+func TestExpvarObserver(t *testing.T) {
+	srv, _ := flakyServer(1, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	name := fmt.Sprintf("customerio_test_%d", atomic.AddInt32(&expvarRuns, 1))
+	track := customerio.NewTrackClient("site_id", "api_key",
+		customerio.WithRetry(testRetryPolicy),
+		customerio.WithObserver(customerio.NewExpvarObserver(name)))
+	track.URL = srv.URL
+
+	if err := track.Identify("1", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var vars map[string]map[string]float64
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &vars); err != nil {
+		t.Fatal(err)
+	}
+	got := vars["PUT /api/v1/customers/{id}"]
+	if got["requests"] != 2 || got["retries"] != 1 || got["5xx"] != 1 || got["2xx"] != 1 || got["duration_seconds"] <= 0 {
+		t.Errorf("Unexpected metrics: %v", vars)
+	}
+}
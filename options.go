@@ -2,29 +2,32 @@ package customerio
 
 import "net/http"
 
-type option struct {
+// Option configures a client. The same options apply to both NewTrackClient and NewAPIClient.
+type Option struct {
 	api   func(*APIClient)
 	track func(*CustomerIO)
 }
 
-type region struct {
+// Region holds the base URLs of the APIs of a Customer.io region.
+// Use RegionUS or RegionEU, or your own Region to point clients at a test server.
+type Region struct {
 	ApiURL   string
 	TrackURL string
 }
 
 var (
-	RegionUS = region{
+	RegionUS = Region{
 		ApiURL:   "https://api.customer.io",
 		TrackURL: "https://track.customer.io",
 	}
-	RegionEU = region{
+	RegionEU = Region{
 		ApiURL:   "https://api-eu.customer.io",
 		TrackURL: "https://track-eu.customer.io",
 	}
 )
 
-func WithRegion(r region) option {
-	return option{
+func WithRegion(r Region) Option {
+	return Option{
 		api: func(a *APIClient) {
 			a.URL = r.ApiURL
 		},
@@ -34,8 +37,8 @@ func WithRegion(r region) option {
 	}
 }
 
-func WithHTTPClient(client *http.Client) option {
-	return option{
+func WithHTTPClient(client *http.Client) Option {
+	return Option{
 		api: func(a *APIClient) {
 			a.Client = client
 		},
@@ -45,8 +48,8 @@ func WithHTTPClient(client *http.Client) option {
 	}
 }
 
-func WithUserAgent(ua string) option {
-	return option{
+func WithUserAgent(ua string) Option {
+	return Option{
 		api: func(a *APIClient) {
 			a.UserAgent = ua
 		},
@@ -56,8 +59,8 @@ func WithUserAgent(ua string) option {
 	}
 }
 
-func WithIDType(idType string) option {
-	return option{
+func WithIDType(idType string) Option {
+	return Option{
 		api: func(a *APIClient) {},
 		track: func(c *CustomerIO) {
 			c.IDType = idType
@@ -0,0 +1,482 @@
+package customerio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	outboxSegmentExt = ".log"
+	outboxAckExt     = ".ack"
+)
+
+var ErrOutboxClosed = errors.New("outbox is closed")
+
+// OutboxConfig configures an Outbox. Zero values take the defaults: 1MB segments,
+// delivered every second.
+type OutboxConfig struct {
+	// SegmentSize is the size in bytes after which a new segment file is started. Defaults to 1MB.
+	SegmentSize int64
+	// Interval is how often Run tries to deliver pending calls. Defaults to 1s.
+	Interval time.Duration
+	// OnError is called by Run when a delivery fails, and for calls dropped
+	// because Customer.io rejected them permanently.
+	OnError func(err error)
+}
+
+// Outbox durably records track calls in append-only segment files before
+// delivering them, so that calls survive process restarts. Calls are delivered
+// in order, at least once: a call is only acknowledged once Customer.io accepted it.
+// Calls rejected with a client error other than 408 or 429 are acknowledged and
+// reported through OnError, since retrying them cannot succeed.
+type Outbox struct {
+	client *CustomerIO
+	dir    string
+	config OutboxConfig
+
+	deliverMu sync.Mutex
+
+	mu       sync.Mutex
+	closed   bool
+	seq      uint64
+	segments []*outboxSegment
+	file     *os.File
+}
+
+// outboxEntry is a single call as stored on disk.
+type outboxEntry struct {
+	Seq    uint64          `json:"seq"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type outboxSegment struct {
+	id      uint64
+	size    int64
+	entries []outboxEntry
+	acked   map[uint64]bool
+	ackFile *os.File
+}
+
+func (s *outboxSegment) done() bool {
+	return len(s.acked) >= len(s.entries)
+}
+
+// OpenOutbox opens, or creates, an outbox stored in dir and delivering through c.
+// Calls left unacknowledged by a previous process are loaded and delivered by the
+// next call to Deliver or Run.
+func OpenOutbox(c *CustomerIO, dir string, config OutboxConfig) (*Outbox, error) {
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = 1 << 20
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	o := &Outbox{
+		client: c,
+		dir:    dir,
+		config: config,
+	}
+	if err := o.load(); err != nil {
+		o.closeFiles()
+		return nil, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.compact(); err != nil {
+		o.closeFiles()
+		return nil, err
+	}
+	return o, nil
+}
+
+// Identify records a call identifying a customer and setting their attributes.
+func (o *Outbox) Identify(customerID string, attributes map[string]interface{}) error {
+	if customerID == "" {
+		return ParamError{Param: "customerID"}
+	}
+	return o.append("PUT",
+		fmt.Sprintf("/api/v1/customers/%s", url.PathEscape(customerID)),
+		attributes)
+}
+
+// Track records an event for the supplied user.
+func (o *Outbox) Track(customerID string, eventName string, data map[string]interface{}) error {
+	if customerID == "" {
+		return ParamError{Param: "customerID"}
+	}
+	if eventName == "" {
+		return ParamError{Param: "eventName"}
+	}
+	return o.append("POST",
+		fmt.Sprintf("/api/v1/customers/%s/events", url.PathEscape(customerID)),
+		map[string]interface{}{
+			"name": eventName,
+			"data": data,
+		})
+}
+
+// TrackAnonymous records an event for the anonymous user.
+func (o *Outbox) TrackAnonymous(anonymousID, eventName string, data map[string]interface{}) error {
+	if eventName == "" {
+		return ParamError{Param: "eventName"}
+	}
+
+	payload := map[string]interface{}{
+		"name": eventName,
+		"data": data,
+	}
+	if anonymousID != "" {
+		payload["anonymous_id"] = anonymousID
+	}
+
+	return o.append("POST", "/api/v1/events", payload)
+}
+
+// AddDevice records adding a device for a customer.
+func (o *Outbox) AddDevice(customerID string, deviceID string, platform string, data map[string]interface{}) error {
+	if customerID == "" {
+		return ParamError{Param: "customerID"}
+	}
+	d, err := newDeviceV1(deviceID, platform, data)
+	if err != nil {
+		return err
+	}
+	return o.append("PUT",
+		fmt.Sprintf("/api/v1/customers/%s/devices", url.PathEscape(customerID)),
+		map[string]interface{}{
+			"device": d,
+		})
+}
+
+// Pending returns the number of calls waiting to be delivered.
+func (o *Outbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := 0
+	for _, s := range o.segments {
+		n += len(s.entries) - len(s.acked)
+	}
+	return n
+}
+
+// Deliver sends pending calls in order. It stops at the first call that fails
+// with a transient error and returns that error; the call is retried next time.
+func (o *Outbox) Deliver(ctx context.Context) error {
+	o.deliverMu.Lock()
+	defer o.deliverMu.Unlock()
+
+	o.mu.Lock()
+	closed := o.closed
+	o.mu.Unlock()
+	if closed {
+		return ErrOutboxClosed
+	}
+
+	for _, e := range o.unacked() {
+		var body interface{}
+		if len(e.entry.Body) > 0 {
+			body = e.entry.Body
+		}
+
+		err := o.client.request(ctx, e.entry.Method, o.client.URL+e.entry.Path, body)
+		if err != nil && !permanentFailure(err) {
+			return err
+		}
+		if err != nil && o.config.OnError != nil {
+			o.config.OnError(err)
+		}
+		if err := o.ack(e.segment, e.entry.Seq); err != nil {
+			return err
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.compact()
+}
+
+// Run delivers pending calls every Interval until ctx is done.
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := o.Deliver(ctx); err != nil && ctx.Err() == nil && o.config.OnError != nil {
+			o.config.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close releases the files held by the outbox. Pending calls stay on disk.
+func (o *Outbox) Close() error {
+	o.deliverMu.Lock()
+	defer o.deliverMu.Unlock()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	return o.closeFiles()
+}
+
+func permanentFailure(err error) bool {
+	var cioErr *CustomerIOError
+	if !errors.As(err, &cioErr) {
+		return false
+	}
+	return cioErr.status >= 400 && cioErr.status < 500 && !retryableStatus(cioErr.status)
+}
+
+type pendingEntry struct {
+	segment *outboxSegment
+	entry   outboxEntry
+}
+
+func (o *Outbox) unacked() []pendingEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var pending []pendingEntry
+	for _, s := range o.segments {
+		for _, e := range s.entries {
+			if !s.acked[e.Seq] {
+				pending = append(pending, pendingEntry{segment: s, entry: e})
+			}
+		}
+	}
+	return pending
+}
+
+func (o *Outbox) append(method, path string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return ErrOutboxClosed
+	}
+
+	active := o.segments[len(o.segments)-1]
+	if active.size >= o.config.SegmentSize {
+		if active, err = o.rotate(); err != nil {
+			return err
+		}
+	}
+
+	entry := outboxEntry{
+		Seq:    o.seq + 1,
+		Method: method,
+		Path:   path,
+		Body:   b,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if _, err := o.file.Write(line); err != nil {
+		return err
+	}
+	if err := o.file.Sync(); err != nil {
+		return err
+	}
+
+	o.seq = entry.Seq
+	active.size += int64(len(line))
+	active.entries = append(active.entries, entry)
+	return nil
+}
+
+func (o *Outbox) ack(s *outboxSegment, seq uint64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if s.ackFile == nil {
+		f, err := os.OpenFile(o.path(s.id, outboxAckExt), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		s.ackFile = f
+	}
+	if _, err := s.ackFile.WriteString(strconv.FormatUint(seq, 10) + "\n"); err != nil {
+		return err
+	}
+	s.acked[seq] = true
+	return nil
+}
+
+// compact removes the files of fully acknowledged segments. A fully acknowledged
+// active segment is replaced by a fresh one so that its files can go too.
+func (o *Outbox) compact() error {
+	active := o.segments[len(o.segments)-1]
+	if active.done() && len(active.entries) > 0 {
+		if _, err := o.rotate(); err != nil {
+			return err
+		}
+	}
+
+	kept := o.segments[:0]
+	for i, s := range o.segments {
+		if i == len(o.segments)-1 || !s.done() {
+			kept = append(kept, s)
+			continue
+		}
+		if s.ackFile != nil {
+			s.ackFile.Close()
+		}
+		if err := os.Remove(o.path(s.id, outboxSegmentExt)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Remove(o.path(s.id, outboxAckExt)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	o.segments = kept
+	return nil
+}
+
+// rotate starts a new active segment.
+func (o *Outbox) rotate() (*outboxSegment, error) {
+	var id uint64 = 1
+	if len(o.segments) > 0 {
+		id = o.segments[len(o.segments)-1].id + 1
+	}
+
+	f, err := os.OpenFile(o.path(id, outboxSegmentExt), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if o.file != nil {
+		o.file.Close()
+	}
+	o.file = f
+
+	s := &outboxSegment{
+		id:    id,
+		acked: map[uint64]bool{},
+	}
+	o.segments = append(o.segments, s)
+	return s, nil
+}
+
+// load reads the existing segments and always starts a new active segment,
+// so that a record torn by a crash is never appended to.
+func (o *Outbox) load() error {
+	files, err := ioutil.ReadDir(o.dir)
+	if err != nil {
+		return err
+	}
+
+	var ids []uint64
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasSuffix(name, outboxSegmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, outboxSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		s, err := o.readSegment(id)
+		if err != nil {
+			return err
+		}
+		o.segments = append(o.segments, s)
+	}
+
+	_, err = o.rotate()
+	return err
+}
+
+func (o *Outbox) readSegment(id uint64) (*outboxSegment, error) {
+	s := &outboxSegment{
+		id:    id,
+		acked: map[uint64]bool{},
+	}
+
+	data, err := ioutil.ReadFile(o.path(id, outboxSegmentExt))
+	if err != nil {
+		return nil, err
+	}
+	s.size = int64(len(data))
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		var e outboxEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// a partially written record can only be the last one.
+			break
+		}
+		s.entries = append(s.entries, e)
+		if e.Seq > o.seq {
+			o.seq = e.Seq
+		}
+	}
+
+	acks, err := ioutil.ReadFile(o.path(id, outboxAckExt))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(acks), "\n") {
+		if seq, err := strconv.ParseUint(line, 10, 64); err == nil {
+			s.acked[seq] = true
+		}
+	}
+	return s, nil
+}
+
+func (o *Outbox) closeFiles() error {
+	var err error
+	if o.file != nil {
+		err = o.file.Close()
+		o.file = nil
+	}
+	for _, s := range o.segments {
+		if s.ackFile != nil {
+			s.ackFile.Close()
+			s.ackFile = nil
+		}
+	}
+	return err
+}
+
+func (o *Outbox) path(id uint64, ext string) string {
+	return filepath.Join(o.dir, fmt.Sprintf("%020d%s", id, ext))
+}
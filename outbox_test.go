@@ -0,0 +1,226 @@
+package customerio_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+type recordedCall struct {
+	method string
+	path   string
+	body   string
+}
+
+func recordingServer(t *testing.T, status func(calls int) int) (*customerio.CustomerIO, *httptest.Server, func() []recordedCall) {
+	var (
+		mu    sync.Mutex
+		calls []recordedCall
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		mu.Lock()
+		calls = append(calls, recordedCall{req.Method, req.URL.RequestURI(), string(b)})
+		n := len(calls)
+		mu.Unlock()
+
+		w.WriteHeader(status(n))
+	}))
+
+	track := customerio.NewTrackClient("site_id", "api_key")
+	track.URL = srv.URL
+
+	return track, srv, func() []recordedCall {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedCall(nil), calls...)
+	}
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestOutboxDeliver(t *testing.T) {
+	track, srv, calls := recordingServer(t, func(int) int { return http.StatusOK })
+	defer srv.Close()
+
+	dir := t.TempDir()
+	outbox, err := customerio.OpenOutbox(track, dir, customerio.OutboxConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+
+	checkParamError(t, outbox.Identify("", nil), "customerID")
+	checkParamError(t, outbox.Track("1", "", nil), "eventName")
+
+	if err := outbox.Identify("1/", map[string]interface{}{"a": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Track("1", "purchase", map[string]interface{}{"price": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.TrackAnonymous("anon", "visit", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.AddDevice("1", "d1", "ios", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := outbox.Pending(); got != 4 {
+		t.Errorf("wrong pending count. got: %d, want: %d", got, 4)
+	}
+
+	if err := outbox.Deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []recordedCall{
+		{"PUT", "/api/v1/customers/1%2F", `{"a":"1"}`},
+		{"POST", "/api/v1/customers/1/events", `{"data":{"price":1},"name":"purchase"}`},
+		{"POST", "/api/v1/events", `{"anonymous_id":"anon","data":null,"name":"visit"}`},
+		{"PUT", "/api/v1/customers/1/devices", `{"device":{"id":"d1","platform":"ios","attributes":null}}`},
+	}
+	got := calls()
+	if len(got) != len(expect) {
+		t.Fatalf("wrong number of calls. got: %#v", got)
+	}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Errorf("call %d differed. want: %#v, got: %#v", i, expect[i], got[i])
+		}
+	}
+
+	if got := outbox.Pending(); got != 0 {
+		t.Errorf("wrong pending count. got: %d, want: %d", got, 0)
+	}
+	if files := segmentFiles(t, dir); len(files) != 1 {
+		t.Errorf("acknowledged segments were not compacted: %v", files)
+	}
+}
+
+func TestOutboxReplayAfterRestart(t *testing.T) {
+	failing := true
+	var mu sync.Mutex
+	track, srv, calls := recordingServer(t, func(n int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing && n > 1 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	defer srv.Close()
+
+	dir := t.TempDir()
+	outbox, err := customerio.OpenOutbox(track, dir, customerio.OutboxConfig{SegmentSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if err := outbox.Track("1", name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := outbox.Deliver(context.Background()); err == nil {
+		t.Fatal("expected delivery to fail")
+	}
+	if got := outbox.Pending(); got != 2 {
+		t.Errorf("wrong pending count. got: %d, want: %d", got, 2)
+	}
+	if err := outbox.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a crash in the middle of writing a record.
+	files := segmentFiles(t, dir)
+	f, err := os.OpenFile(files[len(files)-1], os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"seq":4,"meth`)
+	f.Close()
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+
+	outbox, err = customerio.OpenOutbox(track, dir, customerio.OutboxConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+
+	if got := outbox.Pending(); got != 2 {
+		t.Errorf("wrong pending count after restart. got: %d, want: %d", got, 2)
+	}
+	if err := outbox.Deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := calls()
+	if len(got) != 4 {
+		t.Fatalf("wrong number of calls. got: %#v", got)
+	}
+	if got[2].body != `{"data":null,"name":"b"}` || got[3].body != `{"data":null,"name":"c"}` {
+		t.Errorf("pending calls were not replayed in order: %#v", got[2:])
+	}
+	if files := segmentFiles(t, dir); len(files) != 1 {
+		t.Errorf("acknowledged segments were not compacted: %v", files)
+	}
+}
+
+func TestOutboxDropsRejectedCalls(t *testing.T) {
+	track, srv, calls := recordingServer(t, func(n int) int {
+		if n == 1 {
+			return http.StatusBadRequest
+		}
+		return http.StatusOK
+	})
+	defer srv.Close()
+
+	var dropped []error
+	outbox, err := customerio.OpenOutbox(track, t.TempDir(), customerio.OutboxConfig{
+		OnError: func(err error) {
+			dropped = append(dropped, err)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+
+	outbox.Track("1", "a", nil)
+	outbox.Track("1", "b", nil)
+	if err := outbox.Deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dropped) != 1 {
+		t.Errorf("expected rejected call to be reported, got: %#v", dropped)
+	}
+	if len(calls()) != 2 || outbox.Pending() != 0 {
+		t.Errorf("expected both calls to be acknowledged, got: %#v", calls())
+	}
+
+	outbox.Close()
+	if err := outbox.Track("1", "c", nil); err != customerio.ErrOutboxClosed {
+		t.Errorf("expected ErrOutboxClosed, got: %#v", err)
+	}
+}
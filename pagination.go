@@ -0,0 +1,100 @@
+package customerio
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// ListOption customizes the query string of App API list calls,
+// e.g. the page size or the cursor to start from.
+type ListOption func(q url.Values)
+
+// PageStart sets the pagination cursor to start from, as returned in the Next field of a previous page.
+func PageStart(cursor string) ListOption {
+	return func(q url.Values) {
+		if cursor != "" {
+			q.Set("start", cursor)
+		}
+	}
+}
+
+// PageLimit sets the maximum number of results per page.
+func PageLimit(limit int) ListOption {
+	return func(q url.Values) {
+		if limit > 0 {
+			q.Set("limit", strconv.Itoa(limit))
+		}
+	}
+}
+
+// SortOrder sets the order of the results, "asc" or "desc", on calls supporting it.
+func SortOrder(order string) ListOption {
+	return func(q url.Values) {
+		q.Set("sort", order)
+	}
+}
+
+// listQuery applies opts on top of q, which may be nil.
+func listQuery(q url.Values, opts []ListOption) url.Values {
+	if q == nil {
+		q = url.Values{}
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// withQuery appends the encoded query to requestPath, if any.
+func withQuery(requestPath string, q url.Values) string {
+	if len(q) == 0 {
+		return requestPath
+	}
+	return requestPath + "?" + q.Encode()
+}
+
+// pager follows the Next cursor of paginated list calls on behalf of iterators.
+type pager struct {
+	ctx  context.Context
+	opts []ListOption
+	// fetch requests a page with the given options, hands its items to the
+	// iterator and returns the cursor of the next page along with the item count.
+	fetch func(opts []ListOption) (next string, n int, err error)
+
+	cursor  string
+	started bool
+	done    bool
+	err     error
+}
+
+// more fetches pages until one has items, returning false once the
+// results are exhausted, the context is done or a request failed.
+func (p *pager) more() bool {
+	for !p.done && p.err == nil {
+		if err := p.ctx.Err(); err != nil {
+			p.err = err
+			return false
+		}
+
+		opts := p.opts
+		if p.started {
+			opts = append(opts[:len(opts):len(opts)], PageStart(p.cursor))
+		}
+		next, n, err := p.fetch(opts)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		if next == "" || (p.started && next == p.cursor) {
+			p.done = true
+		}
+		p.started = true
+		p.cursor = next
+
+		if n > 0 {
+			return true
+		}
+	}
+	return false
+}
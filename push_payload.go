@@ -0,0 +1,167 @@
+package customerio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// MaxIOSPayloadSize is the maximum size in bytes of the APNs payload of a push.
+	MaxIOSPayloadSize = 4096
+	// MaxAndroidPayloadSize is the maximum size in bytes of the FCM payload of a push.
+	MaxAndroidPayloadSize = 4096
+)
+
+// IOSAlert is the visible part of an iOS notification.
+type IOSAlert struct {
+	Title    string `json:"title,omitempty"`
+	Subtitle string `json:"subtitle,omitempty"`
+	Body     string `json:"body,omitempty"`
+}
+
+// IOSPayload builds the APNs payload of a push.
+type IOSPayload struct {
+	Alert          *IOSAlert // Alert shown to the user.
+	Badge          *int      // Badge number of the app icon, 0 removes it.
+	Sound          string    // Sound to play, "default" for the system sound.
+	Category       string    // Category of the notification, selecting its actions.
+	MutableContent bool      // Whether a notification service extension may modify the notification.
+	ThreadID       string    // Identifier grouping notifications together.
+
+	// Data holds custom keys, sent next to the aps dictionary.
+	Data map[string]interface{}
+}
+
+// MarshalJSON encodes the payload as expected by APNs.
+func (p IOSPayload) MarshalJSON() ([]byte, error) {
+	aps := struct {
+		Alert          *IOSAlert `json:"alert,omitempty"`
+		Badge          *int      `json:"badge,omitempty"`
+		Sound          string    `json:"sound,omitempty"`
+		Category       string    `json:"category,omitempty"`
+		MutableContent int       `json:"mutable-content,omitempty"`
+		ThreadID       string    `json:"thread-id,omitempty"`
+	}{
+		Alert:    p.Alert,
+		Badge:    p.Badge,
+		Sound:    p.Sound,
+		Category: p.Category,
+		ThreadID: p.ThreadID,
+	}
+	if p.MutableContent {
+		aps.MutableContent = 1
+	}
+
+	m := make(map[string]interface{}, len(p.Data)+1)
+	for k, v := range p.Data {
+		m[k] = v
+	}
+	m["aps"] = aps
+	return json.Marshal(m)
+}
+
+// AndroidPriority is the delivery priority of an Android push.
+type AndroidPriority string
+
+const (
+	AndroidPriorityNormal AndroidPriority = "normal"
+	AndroidPriorityHigh   AndroidPriority = "high"
+)
+
+// AndroidNotification is the visible part of an Android notification.
+type AndroidNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	Image string `json:"image,omitempty"` // URL of an image to display.
+}
+
+// AndroidPayload builds the FCM payload of a push.
+type AndroidPayload struct {
+	Notification *AndroidNotification // Notification shown to the user, nil for data only pushes.
+	Data         map[string]string    // Data handed to the app.
+	Priority     AndroidPriority      // Delivery priority.
+	ChannelID    string               // Notification channel to post the notification to.
+}
+
+// MarshalJSON encodes the payload as expected by FCM.
+func (p AndroidPayload) MarshalJSON() ([]byte, error) {
+	type channel struct {
+		ChannelID string `json:"channel_id"`
+	}
+	type config struct {
+		Priority     AndroidPriority `json:"priority,omitempty"`
+		Notification *channel        `json:"notification,omitempty"`
+	}
+	msg := struct {
+		Notification *AndroidNotification `json:"notification,omitempty"`
+		Data         map[string]string    `json:"data,omitempty"`
+		Android      *config              `json:"android,omitempty"`
+	}{
+		Notification: p.Notification,
+		Data:         p.Data,
+	}
+	if p.Priority != "" || p.ChannelID != "" {
+		msg.Android = &config{Priority: p.Priority}
+		if p.ChannelID != "" {
+			msg.Android.Notification = &channel{ChannelID: p.ChannelID}
+		}
+	}
+
+	return json.Marshal(struct {
+		Message interface{} `json:"message"`
+	}{msg})
+}
+
+// SetCustomPayload sets CustomPayload from the given platform payloads, either of which may be nil.
+// It returns a *ValidationError when a payload exceeds its size limit.
+func (r *SendPushRequest) SetCustomPayload(ios *IOSPayload, android *AndroidPayload) error {
+	b, err := json.Marshal(struct {
+		IOS     *IOSPayload     `json:"ios,omitempty"`
+		Android *AndroidPayload `json:"android,omitempty"`
+	}{ios, android})
+	if err != nil {
+		return err
+	}
+
+	var v validator
+	v.customPayload(b)
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	r.CustomPayload = b
+	return nil
+}
+
+// customPayload checks that the platform payloads of a push fit within their size limits.
+func (v *validator) customPayload(payload json.RawMessage) {
+	if len(payload) == 0 {
+		return
+	}
+
+	var platforms map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &platforms); err != nil {
+		v.add("custom_payload", "invalid JSON: "+err.Error())
+		return
+	}
+
+	limits := []struct {
+		platform string
+		max      int
+	}{{"ios", MaxIOSPayloadSize}, {"android", MaxAndroidPayloadSize}}
+	for _, l := range limits {
+		raw, ok := platforms[l.platform]
+		if !ok {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			v.add("custom_payload."+l.platform, "invalid JSON: "+err.Error())
+			continue
+		}
+		if buf.Len() > l.max {
+			v.add("custom_payload."+l.platform, fmt.Sprintf("size of %d bytes exceeds %d bytes", buf.Len(), l.max))
+		}
+	}
+}
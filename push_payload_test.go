@@ -0,0 +1,153 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestSetCustomPayload(t *testing.T) {
+	badge := 3
+	ios := &customerio.IOSPayload{
+		Alert:          &customerio.IOSAlert{Title: "Order shipped", Body: "Your order is on its way"},
+		Badge:          &badge,
+		Sound:          "default",
+		Category:       "ORDER",
+		MutableContent: true,
+		ThreadID:       "orders",
+		Data:           map[string]interface{}{"order_id": 42},
+	}
+	android := &customerio.AndroidPayload{
+		Notification: &customerio.AndroidNotification{Title: "Order shipped", Body: "Your order is on its way"},
+		Data:         map[string]string{"order_id": "42"},
+		Priority:     customerio.AndroidPriorityHigh,
+		ChannelID:    "orders",
+	}
+
+	var req customerio.SendPushRequest
+	if err := req.SetCustomPayload(ios, android); err != nil {
+		t.Fatal(err)
+	}
+
+	var got, expect interface{}
+	if err := json.Unmarshal(req.CustomPayload, &got); err != nil {
+		t.Fatal(err)
+	}
+	json.Unmarshal([]byte(`{
+		"ios": {
+			"aps": {
+				"alert": {"title": "Order shipped", "body": "Your order is on its way"},
+				"badge": 3,
+				"sound": "default",
+				"category": "ORDER",
+				"mutable-content": 1,
+				"thread-id": "orders"
+			},
+			"order_id": 42
+		},
+		"android": {
+			"message": {
+				"notification": {"title": "Order shipped", "body": "Your order is on its way"},
+				"data": {"order_id": "42"},
+				"android": {"priority": "high", "notification": {"channel_id": "orders"}}
+			}
+		}
+	}`), &expect)
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expect: %v, Got: %v", expect, got)
+	}
+
+	if err := req.SetCustomPayload(nil, &customerio.AndroidPayload{Data: map[string]string{"k": "v"}}); err != nil {
+		t.Fatal(err)
+	}
+	if expect := `{"android":{"message":{"data":{"k":"v"}}}}`; string(req.CustomPayload) != expect {
+		t.Errorf("Expect: %s, Got: %s", expect, req.CustomPayload)
+	}
+}
+
+func TestSetCustomPayloadTooLarge(t *testing.T) {
+	req := customerio.SendPushRequest{CustomPayload: json.RawMessage(`{}`)}
+	err := req.SetCustomPayload(&customerio.IOSPayload{
+		Alert: &customerio.IOSAlert{Body: strings.Repeat("a", customerio.MaxIOSPayloadSize)},
+	}, nil)
+
+	var verr *customerio.ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 1 || verr.Errors[0].Field != "custom_payload.ios" {
+		t.Fatalf("Expected a custom_payload.ios ValidationError, got: %v", err)
+	}
+	if string(req.CustomPayload) != `{}` {
+		t.Errorf("Expected CustomPayload to be left unchanged, got: %s", req.CustomPayload)
+	}
+}
+
+func TestSendPushValidatesBeforeSending(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("request should not be sent")
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	cases := []struct {
+		payload string
+		fields  []string
+	}{
+		{`not json`, []string{"custom_payload"}},
+		{`{"android": {"message": {"data": {"k": "` + strings.Repeat("v", customerio.MaxAndroidPayloadSize) + `"}}}}`, []string{"custom_payload.android"}},
+	}
+	for _, c := range cases {
+		_, err := api.SendPush(context.Background(), &customerio.SendPushRequest{
+			TransactionalMessageID: "1",
+			Identifiers:            map[string]string{"id": "1"},
+			CustomPayload:          json.RawMessage(c.payload),
+		})
+
+		var verr *customerio.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Expected ValidationError, got: %v", err)
+			continue
+		}
+		var fields []string
+		for _, fe := range verr.Errors {
+			fields = append(fields, fe.Field)
+		}
+		if !reflect.DeepEqual(fields, c.fields) {
+			t.Errorf("Expect: %v, Got: %v", c.fields, fields)
+		}
+	}
+}
+
+func TestSendPushOnlyChecksPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"delivery_id": "d1", "queued_at": 1}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	// invalid identifiers are left for the API to reject, as before payload validation.
+	req := &customerio.SendPushRequest{
+		TransactionalMessageID: "1",
+		Identifiers:            map[string]string{},
+	}
+	if _, err := api.SendPush(context.Background(), req); err != nil {
+		t.Errorf("Expected the request to be sent, got: %v", err)
+	}
+	if err := req.Validate(); err == nil {
+		t.Error("Expected Validate to report the identifiers")
+	}
+
+	_, err := api.SendPush(context.Background(), nil)
+	checkParamError(t, err, "req")
+	var nilReq *customerio.SendPushRequest
+	checkParamError(t, nilReq.Validate(), "req")
+}
@@ -0,0 +1,84 @@
+package customerio
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting requests to a steady rate, with bursts.
+// It is safe for concurrent use and can be shared by several clients sending to the same workspace.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing rps requests per second on average
+// and up to burst requests at once. A burst lower than 1 is treated as 1, and an rps
+// of 0 or less disables the limit.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WithRateLimit throttles the requests of a client with l, including retries.
+// Requests wait for their turn until their context is done, rather than failing.
+// Pass the same RateLimiter to every client sharing a rate limit.
+func WithRateLimit(l *RateLimiter) Option {
+	return Option{
+		api: func(a *APIClient) {
+			a.limiter = l
+		},
+		track: func(c *CustomerIO) {
+			c.limiter = l
+		},
+	}
+}
+
+// Wait blocks until a request is allowed or ctx is done, in which case it returns ctx.Err().
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	// take the token now; a negative balance is the delay before it is really available.
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package customerio_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	l := customerio.NewRateLimiter(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 requests in a burst, then 4 more at 100 per second.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected Wait to throttle, took %s", elapsed)
+	}
+}
+
+func TestRateLimiterDeadline(t *testing.T) {
+	l := customerio.NewRateLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("Expected Wait to block until the deadline, returned after %s", elapsed)
+	}
+}
+
+func TestWithRateLimitShared(t *testing.T) {
+	srv, calls := flakyServer(0, http.StatusOK)
+	defer srv.Close()
+
+	limiter := customerio.NewRateLimiter(100, 1)
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRateLimit(limiter))
+	track.URL = srv.URL
+	api := customerio.NewAPIClient("myKey", customerio.WithRateLimit(limiter))
+	api.URL = srv.URL
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := track.Identify("1", nil); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := api.GetCustomerSegments(context.Background(), "1", customerio.IDTypeID); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(calls); got != 6 {
+		t.Errorf("wrong number of requests. got: %d, want: %d", got, 6)
+	}
+	// 1 request right away, then 5 more at 100 per second across both clients.
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Errorf("Expected the shared limit to throttle both clients, took %s", elapsed)
+	}
+}
+
+func TestWithRateLimitRetries(t *testing.T) {
+	srv, calls := flakyServer(2, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	// only the first attempt fits before the deadline.
+	track := customerio.NewTrackClient("site_id", "api_key",
+		customerio.WithRetry(testRetryPolicy),
+		customerio.WithRateLimit(customerio.NewRateLimiter(1, 1)))
+	track.URL = srv.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := track.IdentifyCtx(ctx, "1", nil)
+	if !customerio.IsTemporary(err) {
+		t.Errorf("Expected the error of the first attempt, got: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 1)
+	}
+}
@@ -0,0 +1,275 @@
+package customerio
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a transient error are retried.
+// GET, PUT and DELETE requests are retried on network errors and on the 408, 429, 500,
+// 502, 503 and 504 status codes. POST requests, which may not be safe to send twice,
+// are only retried on the 408, 429 and 503 status codes and on network errors that
+// occur before the request is written. All other responses are returned to the caller as is.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values lower than 2 disable retries.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. It doubles on every subsequent retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, including delays asked for
+	// by a Retry-After header. Zero means DefaultRetryPolicy.MaxBackoff.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy makes up to 3 attempts, backing off from 100ms to at most 5s.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// WithRetry enables automatic retries with exponential backoff and jitter.
+// A Retry-After header sent along with the response is honored when it asks for
+// a longer delay than the computed backoff, up to MaxBackoff.
+func WithRetry(p RetryPolicy) Option {
+	return Option{
+		api: func(a *APIClient) {
+			a.retry = &p
+		},
+		track: func(c *CustomerIO) {
+			c.retry = &p
+		},
+	}
+}
+
+// idempotent reports whether a request with the given method can safely be sent more than once.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryableStatus reports whether a response with the given status code is worth retrying.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// unprocessedStatus reports whether a response with the given status code tells that
+// the request was not processed, so that it can be retried even if it is not idempotent.
+func unprocessedStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay to wait before the given retry (starting at 1).
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	max := p.maxBackoff()
+	d := p.MinBackoff
+	for i := 1; i < retry && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	if d <= 0 {
+		return 0
+	}
+	// equal jitter: wait somewhere between half and the full delay.
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// maxBackoff returns MaxBackoff, or the default one when it is not set.
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return DefaultRetryPolicy.MaxBackoff
+	}
+	return p.MaxBackoff
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// httpConfig holds the options applied to every HTTP call of a client.
+type httpConfig struct {
+	retry      *RetryPolicy
+	limiter    *RateLimiter
+	middleware []Middleware
+	observer   Observer
+	dryRun     DryRunSink
+}
+
+// doHTTP sends the request built by newRequest, retrying according to cfg.retry when it is set.
+// Every attempt first waits on cfg.limiter, if any, then goes through cfg.middleware
+// and is reported to cfg.observer. base is the base URL of the client, which routes are relative to.
+// newRequest is called once per attempt so that every attempt gets a fresh body.
+// The response body is fully read and closed; it is returned alongside the response.
+func doHTTP(ctx context.Context, client *http.Client, base string, cfg httpConfig, newRequest func() (*http.Request, error)) (*http.Response, []byte, error) {
+	p := cfg.retry
+	attempts := 1
+	if p != nil && p.MaxAttempts > 1 {
+		attempts = p.MaxAttempts
+	}
+
+	do := cfg.chain(client)
+
+	var (
+		resp *http.Response
+		body []byte
+		err  error
+	)
+	for attempt := 1; ; attempt++ {
+		if cfg.limiter != nil {
+			if werr := cfg.limiter.Wait(ctx); werr != nil {
+				if attempt == 1 {
+					return nil, nil, werr
+				}
+				// give up on retrying, returning the outcome of the previous attempt.
+				return resp, body, err
+			}
+		}
+
+		req, rerr := newRequest()
+		if rerr != nil {
+			return nil, nil, rerr
+		}
+		var wrote int32
+		if !idempotent(req.Method) {
+			req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+				WroteHeaders: func() { atomic.StoreInt32(&wrote, 1) },
+			}))
+		}
+
+		resp, body, err = observe(cfg.observer, do, base, req, attempt)
+		if attempt >= attempts || !shouldRetry(ctx, req.Method, atomic.LoadInt32(&wrote) == 1, resp, err) {
+			return resp, body, err
+		}
+
+		wait := p.backoff(attempt)
+		if resp != nil {
+			if ra := parseRetryAfter(resp.Header); ra > wait {
+				wait = ra
+				if max := p.maxBackoff(); wait > max {
+					wait = max
+				}
+			}
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			if err != nil {
+				return nil, nil, err
+			}
+			return resp, body, nil
+		case <-t.C:
+		}
+	}
+}
+
+// observe sends req with roundTrip, reporting the attempt to o when it is set.
+func observe(o Observer, do RoundTripFunc, base string, req *http.Request, attempt int) (*http.Response, []byte, error) {
+	if o == nil {
+		return roundTrip(do, req)
+	}
+
+	info := RequestInfo{
+		Method:  req.Method,
+		Route:   routeTemplate(base, req.URL.EscapedPath()),
+		Attempt: attempt,
+	}
+	if ctx := o.OnRequestStart(req.Context(), info); ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	start := time.Now()
+	resp, body, err := roundTrip(do, req)
+
+	result := RequestResult{Duration: time.Since(start), Err: err}
+	if resp != nil {
+		result.StatusCode = resp.StatusCode
+	}
+	o.OnRequestEnd(req.Context(), info, result)
+
+	return resp, body, err
+}
+
+// roundTrip sends req with do and reads the response body. The request body is closed
+// once done, even when a middleware answers without sending req, so that a streamed
+// body stops being written.
+func roundTrip(do RoundTripFunc, req *http.Request) (*http.Response, []byte, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	resp, err := do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+// shouldRetry reports whether an attempt is worth retrying. wrote tells whether any part
+// of the request was written, which makes network errors unsafe to retry for requests
+// that are not idempotent.
+func shouldRetry(ctx context.Context, method string, wrote bool, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return idempotent(method) || !wrote
+	}
+	if !idempotent(method) {
+		return unprocessedStatus(resp.StatusCode)
+	}
+	return retryableStatus(resp.StatusCode)
+}
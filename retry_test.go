@@ -0,0 +1,254 @@
+package customerio_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+var testRetryPolicy = customerio.RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+func flakyServer(failures int32, status int) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	return srv, &calls
+}
+
+func TestTrackRetry(t *testing.T) {
+	srv, calls := flakyServer(2, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRetry(testRetryPolicy))
+	track.URL = srv.URL
+
+	if err := track.Identify("1", map[string]interface{}{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 3)
+	}
+}
+
+func TestTrackRetryExhausted(t *testing.T) {
+	srv, calls := flakyServer(5, http.StatusTooManyRequests)
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRetry(testRetryPolicy))
+	track.URL = srv.URL
+
+	err := track.Track("1", "purchase", nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if _, ok := err.(*customerio.CustomerIOError); !ok {
+		t.Errorf("expected CustomerIOError, got: %#v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 3)
+	}
+}
+
+func TestTrackNoRetryOnClientError(t *testing.T) {
+	srv, calls := flakyServer(5, http.StatusBadRequest)
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRetry(testRetryPolicy))
+	track.URL = srv.URL
+
+	if err := track.Track("1", "purchase", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 1)
+	}
+}
+
+func TestTrackNoRetryByDefault(t *testing.T) {
+	srv, calls := flakyServer(1, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("site_id", "api_key")
+	track.URL = srv.URL
+
+	if err := track.Delete("1"); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 1)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls int32
+	var first, second time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		second = time.Now()
+	}))
+	defer srv.Close()
+
+	policy := testRetryPolicy
+	policy.MaxBackoff = 2 * time.Second
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRetry(policy))
+	track.URL = srv.URL
+
+	if err := track.Identify("1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if d := second.Sub(first); d < time.Second {
+		t.Errorf("Retry-After not honored, retried after %s", d)
+	}
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	for _, max := range []time.Duration{testRetryPolicy.MaxBackoff, 0} {
+		atomic.StoreInt32(&calls, 0)
+		policy := testRetryPolicy
+		policy.MaxBackoff = max
+		track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRetry(policy))
+		track.URL = srv.URL
+
+		start := time.Now()
+		if err := track.Identify("1", nil); err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Since(start); d > 2*customerio.DefaultRetryPolicy.MaxBackoff {
+			t.Errorf("Expected Retry-After to be capped with MaxBackoff %s, took %s", max, d)
+		}
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRetry(testRetryPolicy))
+	track.URL = srv.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := track.IdentifyCtx(ctx, "1", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("retry did not stop on context cancellation, took %s", d)
+	}
+}
+
+func TestAPIRetry(t *testing.T) {
+	srv, calls := flakyServer(1, http.StatusBadGateway)
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithRetry(testRetryPolicy))
+	api.URL = srv.URL
+
+	if _, err := api.ListSegments(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 2)
+	}
+}
+
+func TestAPIRetryNetworkError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{"segments": []}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey", customerio.WithRetry(testRetryPolicy))
+	api.URL = srv.URL
+
+	if _, err := api.ListSegments(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 2)
+	}
+}
+
+func TestTrackNoRetryOnPOSTServerError(t *testing.T) {
+	srv, calls := flakyServer(1, http.StatusInternalServerError)
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRetry(testRetryPolicy))
+	track.URL = srv.URL
+
+	if err := track.Track("1", "purchase", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 1)
+	}
+
+	// PUT is idempotent and still retried.
+	if err := track.Identify("1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 2)
+	}
+}
+
+func TestTrackNoRetryOnPOSTNetworkError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	track := customerio.NewTrackClient("site_id", "api_key", customerio.WithRetry(testRetryPolicy))
+	track.URL = srv.URL
+
+	if err := track.Track("1", "purchase", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("wrong number of attempts. got: %d, want: %d", got, 1)
+	}
+}
@@ -7,8 +7,6 @@ import (
 	"net/http"
 )
 
-const errUnexpectedStatusCode = "unexpected status code %d"
-
 // SegmentState represents the possible states of a segment.
 // Enum values:
 //   - events: currently handling event conditions for this segment
@@ -61,12 +59,12 @@ type CreateSegmentResponse struct {
 
 // CreateSegment sends a request to create a new segment and returns the created segment data.
 func (c *APIClient) CreateSegment(ctx context.Context, req *CreateSegmentRequest) (*CreateSegmentResponse, error) {
-	body, statusCode, err := c.doRequest(ctx, "POST", "/v1/segments", req)
+	resp, body, err := c.doRequest(ctx, "POST", "/v1/segments", req)
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf(errUnexpectedStatusCode, statusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("POST", "/v1/segments", resp, body)
 	}
 
 	var response CreateSegmentResponse
@@ -83,12 +81,12 @@ type ListSegmentsResponse struct {
 
 // ListSegments retrieves all segments from the API.
 func (c *APIClient) ListSegments(ctx context.Context) (*ListSegmentsResponse, error) {
-	respBody, statusCode, err := c.doRequest(ctx, "GET", "/v1/segments", nil)
+	resp, respBody, err := c.doRequest(ctx, "GET", "/v1/segments", nil)
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf(errUnexpectedStatusCode, statusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", "/v1/segments", resp, respBody)
 	}
 
 	var response ListSegmentsResponse
@@ -105,12 +103,13 @@ type GetSegmentResponse struct {
 
 // GetSegment retrieves a specific segment by its ID.
 func (c *APIClient) GetSegment(ctx context.Context, segmentID int) (*GetSegmentResponse, error) {
-	respBody, statusCode, err := c.doRequest(ctx, "GET", fmt.Sprintf("/v1/segments/%d", segmentID), nil)
+	path := fmt.Sprintf("/v1/segments/%d", segmentID)
+	resp, respBody, err := c.doRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf(errUnexpectedStatusCode, statusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", path, resp, respBody)
 	}
 
 	var response GetSegmentResponse
@@ -122,12 +121,13 @@ func (c *APIClient) GetSegment(ctx context.Context, segmentID int) (*GetSegmentR
 
 // DeleteSegment removes a segment by its ID.
 func (c *APIClient) DeleteSegment(ctx context.Context, segmentID int) error {
-	_, statusCode, err := c.doRequest(ctx, "DELETE", fmt.Sprintf("/v1/segments/%d", segmentID), nil)
+	path := fmt.Sprintf("/v1/segments/%d", segmentID)
+	resp, respBody, err := c.doRequest(ctx, "DELETE", path, nil)
 	if err != nil {
 		return err
 	}
-	if statusCode != http.StatusNoContent {
-		return fmt.Errorf(errUnexpectedStatusCode, statusCode)
+	if resp.StatusCode != http.StatusNoContent {
+		return newAPIError("DELETE", path, resp, respBody)
 	}
 	return nil
 }
@@ -143,12 +143,13 @@ type GetSegmentDependenciesResponse struct {
 
 // GetSegmentDependencies returns the dependencies of a specific segment.
 func (c *APIClient) GetSegmentDependencies(ctx context.Context, segmentID int) (*GetSegmentDependenciesResponse, error) {
-	respBody, statusCode, err := c.doRequest(ctx, "GET", fmt.Sprintf("/v1/segments/%d/used_by", segmentID), nil)
+	path := fmt.Sprintf("/v1/segments/%d/used_by", segmentID)
+	resp, respBody, err := c.doRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf(errUnexpectedStatusCode, statusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", path, resp, respBody)
 	}
 
 	var response GetSegmentDependenciesResponse
@@ -165,12 +166,13 @@ type GetSegmentCustomerCountResponse struct {
 
 // GetSegmentCustomerCount returns the total number of customers in a specific segment.
 func (c *APIClient) GetSegmentCustomerCount(ctx context.Context, segmentID int) (*GetSegmentCustomerCountResponse, error) {
-	respBody, statusCode, err := c.doRequest(ctx, "GET", fmt.Sprintf("/v1/segments/%d/customer_count", segmentID), nil)
+	path := fmt.Sprintf("/v1/segments/%d/customer_count", segmentID)
+	resp, respBody, err := c.doRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf(errUnexpectedStatusCode, statusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", path, resp, respBody)
 	}
 
 	var response GetSegmentCustomerCountResponse
@@ -194,14 +196,16 @@ type CustomerIdentifier struct {
 	CioID string `json:"cio_id"` // Customer.io ID of the customer.
 }
 
-// ListCustomersInSegment retrieves a list of customers in a specific segment.
-func (c *APIClient) ListCustomersInSegment(ctx context.Context, segmentID int) (*ListCustomersInSegmentResponse, error) {
-	respBody, statusCode, err := c.doRequest(ctx, "GET", fmt.Sprintf("/v1/segments/%d/membership", segmentID), nil)
+// ListCustomersInSegment retrieves a page of customers in a specific segment.
+// Use PageStart and PageLimit to paginate, or SegmentMembers to iterate over all of them.
+func (c *APIClient) ListCustomersInSegment(ctx context.Context, segmentID int, opts ...ListOption) (*ListCustomersInSegmentResponse, error) {
+	path := withQuery(fmt.Sprintf("/v1/segments/%d/membership", segmentID), listQuery(nil, opts))
+	resp, respBody, err := c.doRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf(errUnexpectedStatusCode, statusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", path, resp, respBody)
 	}
 
 	var response ListCustomersInSegmentResponse
@@ -210,3 +214,62 @@ func (c *APIClient) ListCustomersInSegment(ctx context.Context, segmentID int) (
 	}
 	return &response, nil
 }
+
+// SegmentMemberIterator iterates over the members of a segment, fetching pages as needed.
+//
+//	it := client.SegmentMembers(ctx, segmentID)
+//	for it.Next() {
+//		fmt.Println(it.Identifier().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle error
+//	}
+type SegmentMemberIterator struct {
+	pager
+	page []CustomerIdentifier
+	cur  CustomerIdentifier
+}
+
+// SegmentMembers returns an iterator over all the members of a segment, following
+// the pagination cursor until the results are exhausted or ctx is done.
+// PageLimit sets the size of the pages requested.
+func (c *APIClient) SegmentMembers(ctx context.Context, segmentID int, opts ...ListOption) *SegmentMemberIterator {
+	it := &SegmentMemberIterator{}
+	it.pager = pager{
+		ctx:  ctx,
+		opts: opts,
+		fetch: func(opts []ListOption) (string, int, error) {
+			resp, err := c.ListCustomersInSegment(ctx, segmentID, opts...)
+			if err != nil {
+				return "", 0, err
+			}
+			it.page = resp.Identifiers
+			if len(it.page) == 0 {
+				for _, id := range resp.IDs {
+					it.page = append(it.page, CustomerIdentifier{ID: id})
+				}
+			}
+			return resp.Next, len(it.page), nil
+		},
+	}
+	return it
+}
+
+// Next advances to the next member, returning false when there are no more members or an error occurred.
+func (it *SegmentMemberIterator) Next() bool {
+	if len(it.page) == 0 && !it.more() {
+		return false
+	}
+	it.cur, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Identifier returns the current member.
+func (it *SegmentMemberIterator) Identifier() CustomerIdentifier {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *SegmentMemberIterator) Err() error {
+	return it.err
+}
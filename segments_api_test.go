@@ -3,6 +3,7 @@ package customerio_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -548,6 +549,128 @@ func TestListCustomersInSegmentUnmarshalError(t *testing.T) {
 	}
 }
 
+func TestSegmentAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors": [{"detail": "segment not found", "status": "404"}]}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	err := api.DeleteSegment(context.Background(), notFoundID)
+
+	var apiErr *customerio.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got: %#v", err)
+	}
+	expect := &customerio.APIError{
+		StatusCode: http.StatusNotFound,
+		Method:     "DELETE",
+		Endpoint:   "/v1/segments/2",
+		Messages:   []string{"segment not found"},
+		Body:       []byte(`{"errors": [{"detail": "segment not found", "status": "404"}]}`),
+	}
+	// the response headers, only reachable through accessors, are left out.
+	got := &customerio.APIError{
+		StatusCode: apiErr.StatusCode,
+		Method:     apiErr.Method,
+		Endpoint:   apiErr.Endpoint,
+		Messages:   apiErr.Messages,
+		Body:       apiErr.Body,
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, got)
+	}
+	if !customerio.IsNotFound(err) {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+}
+
+func TestListCustomersInSegmentPagination(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.RequestURI() != "/v1/segments/1/membership?limit=2&start=abc" {
+			t.Errorf("Unexpected request: %s", req.URL.RequestURI())
+		}
+		w.Write([]byte(`{"identifiers": [{"id": "1"}], "next": ""}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	if _, err := api.ListCustomersInSegment(context.Background(), testSegmentID, customerio.PageLimit(2), customerio.PageStart("abc")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSegmentMembers(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"identifiers": [{"id": "1"}, {"id": "2"}], "next": "p2"}`,
+		"p2": `{"identifiers": [], "next": "p3"}`,
+		"p3": `{"ids": ["3"], "next": ""}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/segments/1/membership" || req.URL.Query().Get("limit") != "2" {
+			t.Errorf("Unexpected request: %s", req.URL.RequestURI())
+		}
+		w.Write([]byte(pages[req.URL.Query().Get("start")]))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	var ids []string
+	it := api.SegmentMembers(context.Background(), testSegmentID, customerio.PageLimit(2))
+	for it.Next() {
+		ids = append(ids, it.Identifier().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"1", "2", "3"}) {
+		t.Errorf("Expect: %v, Got: %v", []string{"1", "2", "3"}, ids)
+	}
+}
+
+func TestSegmentMembersError(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"identifiers": [{"id": "1"}], "next": "p2"}`))
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	it := api.SegmentMembers(context.Background(), testSegmentID)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 member before the error, got: %d", n)
+	}
+	var apiErr *customerio.APIError
+	if !errors.As(it.Err(), &apiErr) {
+		t.Errorf("Expected APIError, got: %#v", it.Err())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it = api.SegmentMembers(ctx, testSegmentID)
+	if it.Next() || it.Err() != context.Canceled {
+		t.Errorf("Expected context.Canceled, got: %#v", it.Err())
+	}
+}
+
 func intPtr(s int) *int {
 	return &s
 }
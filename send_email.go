@@ -1,11 +1,9 @@
 package customerio
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
-	"errors"
-	"io"
+	"fmt"
+	"sort"
 )
 
 type SendEmailRequest struct {
@@ -31,29 +29,61 @@ type SendEmailRequest struct {
 	DisableCSSPreprocessing *bool                  `json:"disable_css_preprocessing,omitempty"`
 	SendAt                  *int64                 `json:"send_at,omitempty"`
 	Language                *string                `json:"language,omitempty"`
+
+	files []fileAttachment
 }
 
-var ErrAttachmentExists = errors.New("attachment with this name already exists")
+// Validate checks the request for mistakes the API would reject. It returns
+// a *ValidationError listing every invalid field.
+func (e *SendEmailRequest) Validate() error {
+	if e == nil {
+		return ParamError{Param: "req"}
+	}
+	var v validator
+
+	v.identifiers(e.Identifiers)
+	v.address("from", e.From, false)
+	v.address("to", e.To, true)
+	v.address("reply_to", e.ReplyTo, false)
+	v.address("bcc", e.BCC, true)
+	v.sendAt(e.SendAt)
+
+	if e.TransactionalMessageID == "" {
+		if e.From == "" {
+			v.add("from", "required without transactional_message_id")
+		}
+		if e.Subject == "" {
+			v.add("subject", "required without transactional_message_id")
+		}
+		if e.Body == "" {
+			v.add("body", "required without transactional_message_id")
+		}
+	} else if e.Body != "" || e.PlaintextBody != "" || e.AMPBody != "" {
+		v.add("body", "cannot be set along with transactional_message_id")
+	}
 
-func (e *SendEmailRequest) Attach(name string, value io.Reader) error {
-	if e.Attachments == nil {
-		e.Attachments = map[string]string{}
+	if e.attachmentCount() > MaxAttachments {
+		v.add("attachments", fmt.Sprintf("at most %d attachments are allowed", MaxAttachments))
 	}
-	if _, ok := e.Attachments[name]; ok {
-		return ErrAttachmentExists
+	sizes := e.attachmentSizes()
+	names := make([]string, 0, len(sizes))
+	for name := range sizes {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	var buf bytes.Buffer
-	enc := base64.NewEncoder(base64.StdEncoding, &buf)
-	if _, err := io.Copy(enc, value); err != nil {
-		return err
+	total := 0
+	for _, name := range names {
+		if sizes[name] > MaxAttachmentsSize {
+			v.add("attachments."+name, fmt.Sprintf("size of %d bytes exceeds %d bytes", sizes[name], MaxAttachmentsSize))
+		}
+		total += sizes[name]
 	}
-	if err := enc.Close(); err != nil {
-		return err
+	if total > MaxAttachmentsSize {
+		v.add("attachments", fmt.Sprintf("total size of %d bytes exceeds %d bytes", total, MaxAttachmentsSize))
 	}
 
-	e.Attachments[name] = buf.String()
-	return nil
+	return v.err()
 }
 
 type SendEmailResponse struct {
@@ -61,7 +91,12 @@ type SendEmailResponse struct {
 }
 
 // SendEmail sends a single transactional email using the Customer.io transactional API
+// The request is checked with Validate before being sent.
 func (c *APIClient) SendEmail(ctx context.Context, req *SendEmailRequest) (*SendEmailResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
 	resp, err := c.sendTransactional(ctx, TransactionalTypeEmail, req)
 	if err != nil {
 		return nil, err
@@ -3,9 +3,11 @@ package customerio_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -88,3 +90,106 @@ func TestSendEmailError(t *testing.T) {
 		t.Errorf("Expected TransactionalError, got: %#v", e)
 	}
 }
+
+func TestSendEmailValidate(t *testing.T) {
+	past := time.Now().Add(-time.Hour).Unix()
+	future := time.Now().Add(time.Hour).Unix()
+
+	valid := func() *customerio.SendEmailRequest {
+		return &customerio.SendEmailRequest{
+			Identifiers: map[string]string{"id": "customer_1"},
+			To:          "Customer <customer@example.com>",
+			From:        "business@example.com",
+			Subject:     "hello",
+			Body:        "hello",
+		}
+	}
+
+	cases := []struct {
+		name   string
+		modify func(r *customerio.SendEmailRequest)
+		fields []string
+	}{
+		{"valid inline", func(r *customerio.SendEmailRequest) {}, nil},
+		{"valid template", func(r *customerio.SendEmailRequest) {
+			r.TransactionalMessageID = "1"
+			r.Body, r.Subject, r.From = "", "", ""
+			r.SendAt = &future
+		}, nil},
+		{"missing identifiers", func(r *customerio.SendEmailRequest) { r.Identifiers = nil }, []string{"identifiers"}},
+		{"unknown identifier", func(r *customerio.SendEmailRequest) { r.Identifiers = map[string]string{"phone": "1"} }, []string{"identifiers"}},
+		{"several identifiers", func(r *customerio.SendEmailRequest) {
+			r.Identifiers = map[string]string{"id": "1", "email": "a@example.com"}
+		}, []string{"identifiers"}},
+		{"invalid addresses", func(r *customerio.SendEmailRequest) {
+			r.From = "business"
+			r.To = "a@example.com, nope"
+			r.ReplyTo = "@example.com"
+			r.BCC = "b@example.com, c@example.com"
+		}, []string{"from", "to", "reply_to"}},
+		{"inline without content", func(r *customerio.SendEmailRequest) {
+			r.Subject, r.Body, r.From = "", "", ""
+		}, []string{"from", "subject", "body"}},
+		{"template with body", func(r *customerio.SendEmailRequest) {
+			r.TransactionalMessageID = "1"
+		}, []string{"body"}},
+		{"send at in the past", func(r *customerio.SendEmailRequest) { r.SendAt = &past }, []string{"send_at"}},
+		{"attachments too large", func(r *customerio.SendEmailRequest) {
+			r.Attachments = map[string]string{
+				"a.bin": strings.Repeat("A", 2*1024*1024),
+				"b.bin": strings.Repeat("A", 2*1024*1024),
+			}
+		}, []string{"attachments"}},
+	}
+
+	for _, c := range cases {
+		req := valid()
+		c.modify(req)
+
+		err := req.Validate()
+		if c.fields == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", c.name, err)
+			}
+			continue
+		}
+
+		var verr *customerio.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: expected ValidationError, got: %#v", c.name, err)
+			continue
+		}
+		var fields []string
+		for _, fe := range verr.Errors {
+			fields = append(fields, fe.Field)
+		}
+		if !reflect.DeepEqual(fields, c.fields) {
+			t.Errorf("%s: wrong invalid fields. want: %v, got: %v", c.name, c.fields, fields)
+		}
+	}
+}
+
+func TestSendEmailValidatesBeforeSending(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("request should not be sent")
+	}))
+	defer srv.Close()
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	_, err := api.SendEmail(context.Background(), &customerio.SendEmailRequest{})
+	if _, ok := err.(*customerio.ValidationError); !ok {
+		t.Errorf("Expected ValidationError, got: %#v", err)
+	}
+}
+
+func TestSendEmailNilRequest(t *testing.T) {
+	api := customerio.NewAPIClient("myKey")
+	api.URL = "http://invalid"
+
+	var req *customerio.SendEmailRequest
+	checkParamError(t, req.Validate(), "req")
+	_, err := api.SendEmail(context.Background(), nil)
+	checkParamError(t, err, "req")
+}
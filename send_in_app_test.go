@@ -0,0 +1,61 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestSendInApp(t *testing.T) {
+	inAppRequest := &customerio.SendInAppRequest{
+		TransactionalMessageID: "welcome",
+		Identifiers: map[string]string{
+			"email": "customer@example.com",
+		},
+		MessageData: map[string]interface{}{
+			"name": "gopher",
+		},
+	}
+
+	var verify = func(request []byte) {
+		var body customerio.SendInAppRequest
+		if err := json.Unmarshal(request, &body); err != nil {
+			t.Error(err)
+		}
+
+		if !reflect.DeepEqual(&body, inAppRequest) {
+			t.Errorf("Request differed, want: %#v, got: %#v", request, body)
+		}
+	}
+
+	api, srv := transactionalRouteServer(t, "/v1/send/in_app", verify)
+	defer srv.Close()
+
+	resp, err := api.SendInApp(context.Background(), inAppRequest)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expect := &customerio.SendInAppResponse{
+		TransactionalResponse: customerio.TransactionalResponse{
+			DeliveryID: testDeliveryID,
+			QueuedAt:   time.Unix(int64(testQueuedAt), 0),
+		},
+	}
+
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+}
+
+func TestSendInAppNilRequest(t *testing.T) {
+	api := customerio.NewAPIClient("myKey")
+	api.URL = "http://invalid"
+
+	_, err := api.SendInApp(context.Background(), nil)
+	checkParamError(t, err, "req")
+}
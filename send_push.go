@@ -22,16 +22,43 @@ type SendPushRequest struct {
 	Link          string          `json:"link,omitempty"`
 	CustomData    json.RawMessage `json:"custom_data,omitempty"`
 	CustomPayload json.RawMessage `json:"custom_payload,omitempty"`
-	Device        *deviceV2       `json:"custom_device,omitempty"`
+	Device        *Device         `json:"custom_device,omitempty"`
 	Sound         string          `json:"sound,omitempty"`
 }
 
+// Validate checks the request for mistakes the API would reject, including
+// custom payloads exceeding MaxIOSPayloadSize or MaxAndroidPayloadSize.
+// It returns a *ValidationError listing every invalid field.
+func (r *SendPushRequest) Validate() error {
+	if r == nil {
+		return ParamError{Param: "req"}
+	}
+	var v validator
+
+	v.identifiers(r.Identifiers)
+	v.sendAt(r.SendAt)
+	v.customPayload(r.CustomPayload)
+
+	return v.err()
+}
+
 type SendPushResponse struct {
 	TransactionalResponse
 }
 
 // SendPush sends a single transactional push using the Customer.io transactional API
+// A custom payload that is not valid JSON or exceeds its size limit is rejected before
+// the request is sent; call Validate to check the other fields as well.
 func (c *APIClient) SendPush(ctx context.Context, req *SendPushRequest) (*SendPushResponse, error) {
+	if req == nil {
+		return nil, ParamError{Param: "req"}
+	}
+	var v validator
+	v.customPayload(req.CustomPayload)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
 	resp, err := c.sendTransactional(ctx, TransactionalTypePush, req)
 	if err != nil {
 		return nil, err
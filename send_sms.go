@@ -0,0 +1,38 @@
+package customerio
+
+import "context"
+
+type SendSMSRequest struct {
+	MessageData             map[string]interface{} `json:"message_data,omitempty"`
+	TransactionalMessageID  string                 `json:"transactional_message_id,omitempty"`
+	Identifiers             map[string]string      `json:"identifiers"`
+	To                      string                 `json:"to,omitempty"`
+	From                    string                 `json:"from,omitempty"`
+	Body                    string                 `json:"body,omitempty"`
+	DisableMessageRetention *bool                  `json:"disable_message_retention,omitempty"`
+	SendToUnsubscribed      *bool                  `json:"send_to_unsubscribed,omitempty"`
+	EnableTracking          *bool                  `json:"tracked,omitempty"`
+	QueueDraft              *bool                  `json:"queue_draft,omitempty"`
+	SendAt                  *int64                 `json:"send_at,omitempty"`
+	Language                *string                `json:"language,omitempty"`
+}
+
+type SendSMSResponse struct {
+	TransactionalResponse
+}
+
+// SendSMS sends a single transactional SMS using the Customer.io transactional API.
+// To is the recipient's phone number in E.164 format, e.g. +15555551234.
+func (c *APIClient) SendSMS(ctx context.Context, req *SendSMSRequest) (*SendSMSResponse, error) {
+	if req == nil {
+		return nil, ParamError{Param: "req"}
+	}
+	resp, err := c.sendTransactional(ctx, TransactionalTypeSMS, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SendSMSResponse{
+		*resp,
+	}, nil
+}
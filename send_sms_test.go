@@ -0,0 +1,67 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func TestSendSMS(t *testing.T) {
+	sendAt := time.Now().Add(time.Hour).Unix()
+	queueDraft := true
+	smsRequest := &customerio.SendSMSRequest{
+		TransactionalMessageID: "otp",
+		Identifiers: map[string]string{
+			"id": "customer_1",
+		},
+		To:   "+15555551234",
+		Body: "Your code is {{ trigger.code }}",
+		MessageData: map[string]interface{}{
+			"code": "123456",
+		},
+		SendAt:     &sendAt,
+		QueueDraft: &queueDraft,
+	}
+
+	var verify = func(request []byte) {
+		var body customerio.SendSMSRequest
+		if err := json.Unmarshal(request, &body); err != nil {
+			t.Error(err)
+		}
+
+		if !reflect.DeepEqual(&body, smsRequest) {
+			t.Errorf("Request differed, want: %#v, got: %#v", request, body)
+		}
+	}
+
+	api, srv := transactionalRouteServer(t, "/v1/send/sms", verify)
+	defer srv.Close()
+
+	resp, err := api.SendSMS(context.Background(), smsRequest)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expect := &customerio.SendSMSResponse{
+		TransactionalResponse: customerio.TransactionalResponse{
+			DeliveryID: testDeliveryID,
+			QueuedAt:   time.Unix(int64(testQueuedAt), 0),
+		},
+	}
+
+	if !reflect.DeepEqual(resp, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, resp)
+	}
+}
+
+func TestSendSMSNilRequest(t *testing.T) {
+	api := customerio.NewAPIClient("myKey")
+	api.URL = "http://invalid"
+
+	_, err := api.SendSMS(context.Background(), nil)
+	checkParamError(t, err, "req")
+}
@@ -14,11 +14,15 @@ type TransactionalType int
 const (
 	TransactionalTypeEmail = 0
 	TransactionalTypePush  = 1
+	TransactionalTypeSMS   = 2
+	TransactionalTypeInApp = 3
 )
 
 var typeToApi = map[TransactionalType]string{
 	TransactionalTypeEmail: "email",
 	TransactionalTypePush:  "push",
+	TransactionalTypeSMS:   "sms",
+	TransactionalTypeInApp: "in_app",
 }
 
 var ErrInvalidTransactionalMessageType = errors.New("unknown transactional message type")
@@ -29,12 +33,14 @@ func (c *APIClient) sendTransactional(ctx context.Context, typ TransactionalType
 		return nil, ErrInvalidTransactionalMessageType
 	}
 
-	body, statusCode, err := c.doRequest(ctx, "POST", fmt.Sprintf("/v1/send/%s", api), req)
+	path := fmt.Sprintf("/v1/send/%s", api)
+	httpResp, body, err := c.doRequest(ctx, "POST", path, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
+	if httpResp.StatusCode != http.StatusOK {
+		apiErr := newAPIError("POST", path, httpResp, body)
 		var meta struct {
 			Meta struct {
 				Err string `json:"error"`
@@ -42,13 +48,15 @@ func (c *APIClient) sendTransactional(ctx context.Context, typ TransactionalType
 		}
 		if err := json.Unmarshal(body, &meta); err != nil {
 			return nil, &TransactionalError{
-				StatusCode: statusCode,
+				StatusCode: httpResp.StatusCode,
 				Err:        string(body),
+				apiErr:     apiErr,
 			}
 		}
 		return nil, &TransactionalError{
-			StatusCode: statusCode,
+			StatusCode: httpResp.StatusCode,
 			Err:        meta.Meta.Err,
+			apiErr:     apiErr,
 		}
 	}
 
@@ -87,8 +95,21 @@ type TransactionalError struct {
 	Err string
 	// StatusCode is the http status code for the error.
 	StatusCode int
+
+	apiErr *APIError
 }
 
 func (e *TransactionalError) Error() string {
 	return e.Err
 }
+
+// Unwrap gives access to the underlying *APIError with errors.As.
+func (e *TransactionalError) Unwrap() error {
+	if e.apiErr == nil {
+		return nil
+	}
+	return e.apiErr
+}
+
+// Is reports whether the error matches one of ErrRateLimited, ErrAuth, ErrNotFound or ErrTemporary.
+func (e *TransactionalError) Is(target error) bool { return statusIs(e.StatusCode, target) }
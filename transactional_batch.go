@@ -0,0 +1,116 @@
+package customerio
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultTransactionalRateLimit is the number of requests per second accepted by the transactional API.
+const DefaultTransactionalRateLimit = 100
+
+// SendBatchConfig configures SendEmailBatch and SendPushBatch. Zero values take the defaults:
+// 10 workers sending at most DefaultTransactionalRateLimit (100) requests per second.
+type SendBatchConfig struct {
+	// Workers is the number of requests in flight at once. Defaults to 10.
+	Workers int
+	// RateLimit is the maximum number of requests sent per second. Defaults to DefaultTransactionalRateLimit.
+	RateLimit float64
+}
+
+// SendEmailResult is the outcome of a single email of a batch.
+type SendEmailResult struct {
+	// Response is set when the email was queued.
+	Response *SendEmailResponse
+	// Err is nil when the email was queued, typically a *TransactionalError or a *ValidationError otherwise.
+	Err error
+}
+
+// SendPushResult is the outcome of a single push of a batch.
+type SendPushResult struct {
+	// Response is set when the push was queued.
+	Response *SendPushResponse
+	// Err is nil when the push was queued, typically a *TransactionalError or a *ValidationError otherwise.
+	Err error
+}
+
+// SendEmailBatch sends many transactional emails concurrently, without exceeding the rate limit of the API.
+// The returned results have the same length and order as reqs. When at least one email
+// failed, a *BatchError is returned and the failed results carry their error.
+func (c *APIClient) SendEmailBatch(ctx context.Context, reqs []*SendEmailRequest, config SendBatchConfig) ([]SendEmailResult, error) {
+	results := make([]SendEmailResult, len(reqs))
+	errs := c.sendBatch(ctx, len(reqs), config, func(i int) (err error) {
+		results[i].Response, err = c.SendEmail(ctx, reqs[i])
+		return err
+	})
+	for i, err := range errs {
+		results[i].Err = err
+	}
+	return results, batchError(errs)
+}
+
+// SendPushBatch sends many transactional pushes concurrently, without exceeding the rate limit of the API.
+// The returned results have the same length and order as reqs. When at least one push
+// failed, a *BatchError is returned and the failed results carry their error.
+func (c *APIClient) SendPushBatch(ctx context.Context, reqs []*SendPushRequest, config SendBatchConfig) ([]SendPushResult, error) {
+	results := make([]SendPushResult, len(reqs))
+	errs := c.sendBatch(ctx, len(reqs), config, func(i int) (err error) {
+		results[i].Response, err = c.SendPush(ctx, reqs[i])
+		return err
+	})
+	for i, err := range errs {
+		results[i].Err = err
+	}
+	return results, batchError(errs)
+}
+
+// sendBatch calls send for every index below n from a pool of workers, waiting on the rate limit
+// before each call, and returns the errors by index. Calls not started when ctx is done fail with its error.
+func (c *APIClient) sendBatch(ctx context.Context, n int, config SendBatchConfig, send func(i int) error) []error {
+	if config.Workers <= 0 {
+		config.Workers = 10
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = DefaultTransactionalRateLimit
+	}
+	if config.Workers > n {
+		config.Workers = n
+	}
+
+	limit := NewRateLimiter(config.RateLimit, config.Workers)
+	indexes := make(chan int)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for w := 0; w < config.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if errs[i] = limit.Wait(ctx); errs[i] == nil {
+					errs[i] = send(i)
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
+
+// batchError returns a *BatchError when at least one of errs is set.
+func batchError(errs []error) error {
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return &BatchError{Failed: failed, Total: len(errs)}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+// concurrencyServer answers transactional sends with the customer id as delivery id,
+// failing for customer "fail", and records the highest number of concurrent requests.
+func concurrencyServer(t *testing.T) (*customerio.APIClient, *httptest.Server, *int32) {
+	var inflight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		var body struct {
+			Identifiers map[string]string `json:"identifiers"`
+		}
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Error(err)
+		}
+
+		id := body.Identifiers["id"]
+		if id == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"meta": {"error": "invalid message"}}`))
+			return
+		}
+		w.Write([]byte(`{"delivery_id": "` + id + `", "queued_at": 1}`))
+	}))
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	return api, srv, &peak
+}
+
+func TestSendEmailBatch(t *testing.T) {
+	api, srv, peak := concurrencyServer(t)
+	defer srv.Close()
+
+	var reqs []*customerio.SendEmailRequest
+	for i := 0; i < 20; i++ {
+		id := strconv.Itoa(i)
+		switch i {
+		case 5:
+			id = "fail"
+		case 7:
+			id = ""
+		}
+		reqs = append(reqs, &customerio.SendEmailRequest{
+			TransactionalMessageID: "1",
+			Identifiers:            map[string]string{"id": id},
+		})
+	}
+
+	results, err := api.SendEmailBatch(context.Background(), reqs, customerio.SendBatchConfig{Workers: 3})
+
+	var batchErr *customerio.BatchError
+	if !errors.As(err, &batchErr) || batchErr.Failed != 2 || batchErr.Total != 20 {
+		t.Fatalf("Expected 2 of 20 failures, got: %v", err)
+	}
+	if len(results) != len(reqs) {
+		t.Fatalf("Expected %d results, got %d", len(reqs), len(results))
+	}
+	for i, r := range results {
+		switch i {
+		case 5:
+			var txErr *customerio.TransactionalError
+			if !errors.As(r.Err, &txErr) || txErr.Err != "invalid message" {
+				t.Errorf("result %d: expected TransactionalError, got: %v", i, r.Err)
+			}
+		case 7:
+			var verr *customerio.ValidationError
+			if !errors.As(r.Err, &verr) {
+				t.Errorf("result %d: expected ValidationError, got: %v", i, r.Err)
+			}
+		default:
+			if r.Err != nil || r.Response == nil || r.Response.DeliveryID != strconv.Itoa(i) {
+				t.Errorf("result %d: unexpected result: %+v", i, r)
+			}
+		}
+	}
+	if p := atomic.LoadInt32(peak); p > 3 {
+		t.Errorf("Expected at most 3 concurrent requests, got %d", p)
+	}
+}
+
+func TestSendEmailBatchNilRequest(t *testing.T) {
+	api, srv, _ := concurrencyServer(t)
+	defer srv.Close()
+
+	reqs := []*customerio.SendEmailRequest{
+		nil,
+		{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "1"}},
+	}
+	results, err := api.SendEmailBatch(context.Background(), reqs, customerio.SendBatchConfig{})
+	if err == nil {
+		t.Fatal("Expected the nil request to fail")
+	}
+	if pe, ok := results[0].Err.(customerio.ParamError); !ok || pe.Param != "req" {
+		t.Errorf("Expected ParamError, got: %#v", results[0].Err)
+	}
+	if results[1].Err != nil {
+		t.Errorf("Unexpected error: %v", results[1].Err)
+	}
+}
+
+func TestSendPushBatchRateLimit(t *testing.T) {
+	api, srv, _ := concurrencyServer(t)
+	defer srv.Close()
+
+	var reqs []*customerio.SendPushRequest
+	for i := 0; i < 10; i++ {
+		reqs = append(reqs, &customerio.SendPushRequest{
+			TransactionalMessageID: "1",
+			Identifiers:            map[string]string{"id": strconv.Itoa(i)},
+		})
+	}
+
+	// a burst of 2 requests, then 8 more at 50 per second.
+	start := time.Now()
+	results, err := api.SendPushBatch(context.Background(), reqs, customerio.SendBatchConfig{Workers: 2, RateLimit: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("Expected the rate limit to slow the batch down, took %s", elapsed)
+	}
+	for i, r := range results {
+		if r.Response == nil || r.Response.DeliveryID != strconv.Itoa(i) {
+			t.Errorf("result %d: unexpected result: %+v", i, r)
+		}
+	}
+}
+
+func TestSendEmailBatchCanceled(t *testing.T) {
+	api, srv, _ := concurrencyServer(t)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reqs := []*customerio.SendEmailRequest{
+		{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "1"}},
+		{TransactionalMessageID: "1", Identifiers: map[string]string{"id": "2"}},
+	}
+	results, err := api.SendEmailBatch(ctx, reqs, customerio.SendBatchConfig{})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for i, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("result %d: expected context.Canceled, got: %v", i, r.Err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package customerio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// TransactionalMessage represents a transactional message template returned by the API.
+type TransactionalMessage struct {
+	ID                 int    `json:"id"`                   // Unique identifier, usable as TransactionalMessageID.
+	Name               string `json:"name"`                 // Name of the message, also usable as TransactionalMessageID.
+	Description        string `json:"description"`          // Description of the message's purpose.
+	SendToUnsubscribed bool   `json:"send_to_unsubscribed"` // Whether unsubscribed people receive the message.
+	LinkTracking       bool   `json:"link_tracking"`        // Whether links are tracked.
+	OpenTracking       bool   `json:"open_tracking"`        // Whether opens are tracked.
+	HideMessageBody    bool   `json:"hide_message_body"`    // Whether message bodies are hidden from delivery logs.
+	QueueDrafts        bool   `json:"queue_drafts"`         // Whether messages are queued as drafts instead of sent.
+	CreatedAt          int64  `json:"created_at"`           // Unix timestamp of the message creation.
+	UpdatedAt          int64  `json:"updated_at"`           // Unix timestamp of the last update.
+}
+
+// TransactionalMessageContent represents a variant of a transactional message, for a language.
+type TransactionalMessageContent struct {
+	ID        int    `json:"id"`             // Unique identifier for the content.
+	Name      string `json:"name"`           // Name of the variant.
+	Type      string `json:"type"`           // Type of message: email or push.
+	Language  string `json:"language"`       // Language of the variant, empty for the default one.
+	Layout    string `json:"layout"`         // Layout used by the message, for emails.
+	From      string `json:"from"`           // Sender of the message.
+	ReplyTo   string `json:"reply_to"`       // Reply-to address of the message.
+	Recipient string `json:"recipient"`      // Recipient of the message.
+	Subject   string `json:"subject"`        // Subject of the message.
+	Preheader string `json:"preheader_text"` // Preheader of the message, for emails.
+	Body      string `json:"body"`           // Body of the message.
+	AMPBody   string `json:"body_amp"`       // AMP body of the message, for emails.
+	FakeBCC   bool   `json:"fake_bcc"`       // Whether BCC recipients are sent a separate copy.
+	Created   int64  `json:"created"`        // Unix timestamp of the content creation.
+	Updated   int64  `json:"updated"`        // Unix timestamp of the last update.
+}
+
+// ListTransactionalMessagesResponse represents the response containing the transactional messages.
+type ListTransactionalMessagesResponse struct {
+	Messages []TransactionalMessage `json:"messages"` // List of transactional messages.
+}
+
+// ListTransactionalMessages retrieves all transactional messages.
+func (c *APIClient) ListTransactionalMessages(ctx context.Context) (*ListTransactionalMessagesResponse, error) {
+	var response ListTransactionalMessagesResponse
+	if err := c.getJSON(ctx, "/v1/transactional", &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetTransactionalMessageResponse represents the response for retrieving a single transactional message.
+type GetTransactionalMessageResponse struct {
+	Message TransactionalMessage `json:"message"` // The requested message.
+}
+
+// GetTransactionalMessage retrieves a transactional message by the ID or name used as TransactionalMessageID.
+// It returns an error matching ErrNotFound when the message does not exist.
+func (c *APIClient) GetTransactionalMessage(ctx context.Context, transactionalMessageID string) (*GetTransactionalMessageResponse, error) {
+	if transactionalMessageID == "" {
+		return nil, ParamError{Param: "transactionalMessageID"}
+	}
+
+	var response GetTransactionalMessageResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/v1/transactional/%s", url.PathEscape(transactionalMessageID)), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetTransactionalMessageContentsResponse represents the variants of a transactional message.
+type GetTransactionalMessageContentsResponse struct {
+	Contents []TransactionalMessageContent `json:"contents"` // List of contents.
+}
+
+// GetTransactionalMessageContents retrieves the language variants of a transactional message.
+func (c *APIClient) GetTransactionalMessageContents(ctx context.Context, transactionalMessageID string) (*GetTransactionalMessageContentsResponse, error) {
+	if transactionalMessageID == "" {
+		return nil, ParamError{Param: "transactionalMessageID"}
+	}
+
+	var response GetTransactionalMessageContentsResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/v1/transactional/%s/contents", url.PathEscape(transactionalMessageID)), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// UpdateTransactionalMessageContentRequest represents the changes to a transactional message variant.
+// Fields left empty are not changed.
+type UpdateTransactionalMessageContentRequest struct {
+	From      string `json:"from,omitempty"`           // Sender of the message.
+	ReplyTo   string `json:"reply_to,omitempty"`       // Reply-to address of the message.
+	Recipient string `json:"recipient,omitempty"`      // Recipient of the message.
+	Subject   string `json:"subject,omitempty"`        // Subject of the message.
+	Preheader string `json:"preheader_text,omitempty"` // Preheader of the message, for emails.
+	Body      string `json:"body,omitempty"`           // Body of the message.
+	AMPBody   string `json:"body_amp,omitempty"`       // AMP body of the message, for emails.
+	FakeBCC   *bool  `json:"fake_bcc,omitempty"`       // Whether BCC recipients are sent a separate copy.
+}
+
+// UpdateTransactionalMessageContentResponse represents the response for updating a transactional message variant.
+type UpdateTransactionalMessageContentResponse struct {
+	Content TransactionalMessageContent `json:"content"` // The updated content.
+}
+
+// UpdateTransactionalMessageContent updates a variant of a transactional message.
+func (c *APIClient) UpdateTransactionalMessageContent(ctx context.Context, transactionalMessageID string, contentID int, req *UpdateTransactionalMessageContentRequest) (*UpdateTransactionalMessageContentResponse, error) {
+	if transactionalMessageID == "" {
+		return nil, ParamError{Param: "transactionalMessageID"}
+	}
+
+	path := fmt.Sprintf("/v1/transactional/%s/content/%d", url.PathEscape(transactionalMessageID), contentID)
+	resp, respBody, err := c.doRequest(ctx, "PUT", path, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("PUT", path, resp, respBody)
+	}
+
+	var response UpdateTransactionalMessageContentResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
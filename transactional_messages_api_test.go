@@ -0,0 +1,116 @@
+package customerio_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/customerio/go-customerio/v3"
+)
+
+func transactionalMessagesAppServer(t *testing.T, verify func(request []byte)) (*customerio.APIClient, *httptest.Server) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		defer req.Body.Close()
+
+		switch {
+		case req.Method == "GET" && req.URL.Path == "/v1/transactional":
+			w.Write([]byte(`{"messages": [{"id": 1, "name": "receipt", "link_tracking": true, "created_at": 1600000000}]}`))
+		case req.Method == "GET" && req.URL.Path == "/v1/transactional/receipt":
+			w.Write([]byte(`{"message": {"id": 1, "name": "receipt", "link_tracking": true, "created_at": 1600000000}}`))
+		case req.Method == "GET" && req.URL.Path == "/v1/transactional/1/contents":
+			w.Write([]byte(`{"contents": [{"id": 3, "type": "email", "subject": "Your receipt", "body": "<p>Thanks</p>"}]}`))
+		case req.Method == "PUT" && req.URL.Path == "/v1/transactional/1/content/3":
+			verify(b)
+			w.Write([]byte(`{"content": {"id": 3, "type": "email", "subject": "Your new receipt", "body": "<p>Thanks</p>"}}`))
+		case req.Method == "GET" && req.URL.Path == "/v1/transactional/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"meta": {"error": "not found"}}`))
+		default:
+			t.Errorf("Unexpected request: %s %s", req.Method, req.URL.Path)
+		}
+	}))
+
+	api := customerio.NewAPIClient("myKey")
+	api.URL = srv.URL
+
+	return api, srv
+}
+
+func TestListTransactionalMessages(t *testing.T) {
+	api, srv := transactionalMessagesAppServer(t, nil)
+	defer srv.Close()
+
+	message := customerio.TransactionalMessage{ID: 1, Name: "receipt", LinkTracking: true, CreatedAt: 1600000000}
+
+	list, err := api.ListTransactionalMessages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (&customerio.ListTransactionalMessagesResponse{Messages: []customerio.TransactionalMessage{message}}); !reflect.DeepEqual(list, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, list)
+	}
+
+	single, err := api.GetTransactionalMessage(context.Background(), "receipt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (&customerio.GetTransactionalMessageResponse{Message: message}); !reflect.DeepEqual(single, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, single)
+	}
+
+	_, err = api.GetTransactionalMessage(context.Background(), "missing")
+	if !customerio.IsNotFound(err) {
+		t.Errorf("Expected not found error, got: %#v", err)
+	}
+	_, err = api.GetTransactionalMessage(context.Background(), "")
+	checkParamError(t, err, "transactionalMessageID")
+}
+
+func TestTransactionalMessageContents(t *testing.T) {
+	updateRequest := &customerio.UpdateTransactionalMessageContentRequest{
+		Subject: "Your new receipt",
+	}
+
+	var verify = func(request []byte) {
+		var body customerio.UpdateTransactionalMessageContentRequest
+		if err := json.Unmarshal(request, &body); err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(&body, updateRequest) {
+			t.Errorf("Request differed, want: %#v, got: %#v", updateRequest, body)
+		}
+	}
+
+	api, srv := transactionalMessagesAppServer(t, verify)
+	defer srv.Close()
+
+	contents, err := api.GetTransactionalMessageContents(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &customerio.GetTransactionalMessageContentsResponse{
+		Contents: []customerio.TransactionalMessageContent{{ID: 3, Type: "email", Subject: "Your receipt", Body: "<p>Thanks</p>"}},
+	}
+	if !reflect.DeepEqual(contents, expect) {
+		t.Errorf("Expect: %#v, Got: %#v", expect, contents)
+	}
+
+	updated, err := api.UpdateTransactionalMessageContent(context.Background(), "1", 3, updateRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectUpdated := &customerio.UpdateTransactionalMessageContentResponse{
+		Content: customerio.TransactionalMessageContent{ID: 3, Type: "email", Subject: "Your new receipt", Body: "<p>Thanks</p>"},
+	}
+	if !reflect.DeepEqual(updated, expectUpdated) {
+		t.Errorf("Expect: %#v, Got: %#v", expectUpdated, updated)
+	}
+}
@@ -16,7 +16,16 @@ var (
 )
 
 func transactionalServer(t *testing.T, verify func(request []byte)) (*customerio.APIClient, *httptest.Server) {
+	return transactionalRouteServer(t, "", verify)
+}
+
+// transactionalRouteServer is a transactionalServer also checking the request path, unless path is empty.
+func transactionalRouteServer(t *testing.T, path string, verify func(request []byte)) (*customerio.APIClient, *httptest.Server) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if path != "" && (req.Method != "POST" || req.URL.Path != path) {
+			t.Errorf("Unexpected request: %s %s", req.Method, req.URL.Path)
+		}
+
 		b, err := ioutil.ReadAll(req.Body)
 		if err != nil {
 			t.Error(err)
@@ -0,0 +1,84 @@
+package customerio
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// FieldError describes an invalid field of a request.
+type FieldError struct {
+	Field   string // Field is the JSON name of the field.
+	Message string // Message explains what is wrong with it.
+}
+
+func (e FieldError) Error() string { return e.Field + ": " + e.Message }
+
+// ValidationError is returned when a request fails client-side validation. It lists every invalid field.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// validator accumulates field errors.
+type validator struct {
+	errs []FieldError
+}
+
+func (v *validator) add(field, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: message})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+// identifiers checks that ids holds exactly one known IdentifierType with a value.
+func (v *validator) identifiers(ids map[string]string) {
+	if len(ids) == 0 {
+		v.add("identifiers", "missing")
+		return
+	}
+	if len(ids) > 1 {
+		v.add("identifiers", "must contain exactly one of id, email or cio_id")
+		return
+	}
+	for k, val := range ids {
+		if err := (Identifier{Type: IdentifierType(k), Value: val}).validate(); err != nil {
+			v.add("identifiers", err.Error())
+		}
+	}
+}
+
+// address checks that value, if set, is a valid RFC 5322 address, or list of addresses when list is true.
+func (v *validator) address(field, value string, list bool) {
+	if value == "" {
+		return
+	}
+	var err error
+	if list {
+		_, err = mail.ParseAddressList(value)
+	} else {
+		_, err = mail.ParseAddress(value)
+	}
+	if err != nil {
+		v.add(field, "invalid address: "+err.Error())
+	}
+}
+
+// sendAt checks that a scheduled send, if any, is in the future.
+func (v *validator) sendAt(sendAt *int64) {
+	if sendAt != nil && !time.Unix(*sendAt, 0).After(time.Now()) {
+		v.add("send_at", "must be in the future")
+	}
+}